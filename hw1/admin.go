@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/state"
+	"github.com/Keniden/vk-homework/game/user"
+)
+
+// admin - доступны ли игроку команды мастера
+func (g *Game) admin(gamer *user.User) bool {
+	return g.Debug || gamer.Admin
+}
+
+// dump - состояние игрока и его комнаты в том же виде, что и в сохранении
+func (g *Game) dump(gamer *user.User) string {
+	snap := state.Capture(g.World, []*user.User{gamer})
+	res := struct {
+		Turn   int          `json:"turn"`
+		Player state.Player `json:"player"`
+		Room   state.Room   `json:"room"`
+	}{Turn: snap.Turn, Player: snap.Players[0]}
+	for _, r := range snap.Rooms {
+		if r.ID == gamer.InPlace.ID {
+			res.Room = r
+		}
+	}
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// rooms - все комнаты мира по порядку описания и игроки в них
+func (g *Game) rooms(gamer *user.User) string {
+	lines := []string{gamer.T("admin.rooms")}
+	for _, r := range g.World.Rooms {
+		names := make([]string, 0, len(r.Visitors))
+		for _, v := range r.Visitors {
+			names = append(names, v.Nick())
+		}
+		sort.Strings(names)
+		line := r.ID
+		if len(names) > 0 {
+			line += ": " + strings.Join(names, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Keniden/vk-homework/game/user"
+)
+
+/*
+	http фронтенд к игре, сессия - это игрок, которого ведёт клиент:
+
+	POST   /sessions               {"name": "вася", "lang": "en"} - войти в игру
+	POST   /sessions/{id}/commands {"command": "взять ключи"}     - выполнить команду
+	GET    /sessions/{id}/state                                   - что видит игрок сейчас
+	DELETE /sessions/{id}                                         - выйти из игры
+
+	ответ всегда Reply: text - то же, что ответил бы handleCommand,
+	messages - накопившиеся сообщения, state - состояние из user.View.
+	сессия, к которой не обращались дольше IdleTimeout, закрывается, игрок выходит из игры
+*/
+
+type Reply struct {
+	Session  string     `json:"session,omitempty"`
+	Player   string     `json:"player,omitempty"`
+	Text     string     `json:"text,omitempty"`
+	Messages []string   `json:"messages,omitempty"`
+	State    *user.View `json:"state,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+type API struct {
+	Game        *Game
+	IdleTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	mux      *http.ServeMux
+}
+
+type session struct {
+	player string
+	seen   time.Time
+}
+
+func NewAPI(g *Game) *API {
+	a := &API{Game: g, sessions: make(map[string]*session), mux: http.NewServeMux()}
+	a.mux.HandleFunc("POST /sessions", a.create)
+	a.mux.HandleFunc("POST /sessions/{id}/commands", a.command)
+	a.mux.HandleFunc("GET /sessions/{id}/state", a.state)
+	a.mux.HandleFunc("DELETE /sessions/{id}", a.remove)
+	return a
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.expire(time.Now())
+	a.mux.ServeHTTP(w, r)
+}
+
+// expire закрывает сессии, молчавшие дольше IdleTimeout; проверяется при каждом запросе
+func (a *API) expire(now time.Time) {
+	if a.IdleTimeout <= 0 {
+		return
+	}
+	var idle []string
+	a.mu.Lock()
+	for id, s := range a.sessions {
+		if now.Sub(s.seen) > a.IdleTimeout {
+			delete(a.sessions, id)
+			idle = append(idle, s.player)
+		}
+	}
+	a.mu.Unlock()
+	for _, name := range idle {
+		a.Game.Leave(name)
+	}
+}
+
+func (a *API) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+		Lang string `json:"lang"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		reply(w, http.StatusBadRequest, Reply{Error: err.Error()})
+		return
+	}
+	if _, err := a.Game.Join(req.Name); err != nil {
+		reply(w, http.StatusBadRequest, Reply{Error: err.Error()})
+		return
+	}
+	if req.Lang != "" {
+		if err := a.Game.SetLang(req.Name, req.Lang); err != nil {
+			a.Game.Leave(req.Name)
+			reply(w, http.StatusBadRequest, Reply{Error: err.Error()})
+			return
+		}
+	}
+
+	id := newSessionID()
+	a.mu.Lock()
+	a.sessions[id] = &session{player: req.Name, seen: time.Now()}
+	a.mu.Unlock()
+
+	res := a.view(req.Name, a.Game.Look(req.Name))
+	res.Session = id
+	reply(w, http.StatusCreated, res)
+}
+
+func (a *API) command(w http.ResponseWriter, r *http.Request) {
+	name, ok := a.player(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		reply(w, http.StatusBadRequest, Reply{Error: err.Error()})
+		return
+	}
+	reply(w, http.StatusOK, a.view(name, a.Game.Handle(name, req.Command)))
+}
+
+func (a *API) state(w http.ResponseWriter, r *http.Request) {
+	name, ok := a.player(w, r)
+	if !ok {
+		return
+	}
+	reply(w, http.StatusOK, a.view(name, ""))
+}
+
+func (a *API) remove(w http.ResponseWriter, r *http.Request) {
+	name, ok := a.player(w, r)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	delete(a.sessions, r.PathValue("id"))
+	a.mu.Unlock()
+	a.Game.Leave(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// player - игрок сессии из пути; если сессии нет, клиент уже получил ошибку
+func (a *API) player(w http.ResponseWriter, r *http.Request) (string, bool) {
+	a.mu.Lock()
+	s, ok := a.sessions[r.PathValue("id")]
+	name := ""
+	if ok {
+		s.seen = time.Now()
+		name = s.player
+	}
+	a.mu.Unlock()
+	if !ok {
+		reply(w, http.StatusNotFound, Reply{Error: "unknown session"})
+	}
+	return name, ok
+}
+
+func (a *API) view(name, text string) Reply {
+	res := Reply{Player: name, Text: text, Messages: a.Game.Messages(name)}
+	if v, ok := a.Game.View(name); ok {
+		res.State = &v
+	}
+	return res
+}
+
+func reply(w http.ResponseWriter, status int, res Reply) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
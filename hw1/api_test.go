@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keniden/vk-homework/game/user"
+)
+
+func call(t *testing.T, h http.Handler, method, path, body string) (int, Reply) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var res Reply
+	if rec.Code != http.StatusNoContent {
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%s %s: bad json %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code, res
+}
+
+func TestAPI(t *testing.T) {
+	api := NewAPI(newTestGame(t))
+
+	code, res := call(t, api, "POST", "/sessions", `{"name": "вася"}`)
+	if code != http.StatusCreated || res.Session == "" {
+		t.Fatalf("create: %d %+v", code, res)
+	}
+	if want := "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"; res.Text != want {
+		t.Errorf("create text\n\tresult:   %s\n\texpected: %s", res.Text, want)
+	}
+	vasya := "/sessions/" + res.Session
+
+	if code, _ := call(t, api, "POST", "/sessions", `{"name": "вася"}`); code != http.StatusBadRequest {
+		t.Errorf("duplicate player: %d", code)
+	}
+	if code, _ := call(t, api, "POST", "/sessions", `{"name": "петя", "lang": "de"}`); code != http.StatusBadRequest {
+		t.Errorf("unknown language: %d", code)
+	}
+	code, res = call(t, api, "POST", "/sessions", `{"name": "петя", "lang": "en"}`)
+	if code != http.StatusCreated || !strings.HasPrefix(res.Text, "you are in the kitchen") {
+		t.Fatalf("create en: %d %+v", code, res)
+	}
+	petya := "/sessions/" + res.Session
+
+	for _, cmd := range []string{"идти коридор", "идти комната", "надеть рюкзак"} {
+		call(t, api, "POST", vasya+"/commands", `{"command": "`+cmd+`"}`)
+	}
+	_, res = call(t, api, "POST", vasya+"/commands", `{"command": "взять ключи"}`)
+	if res.Text != "предмет добавлен в инвентарь: ключи" {
+		t.Errorf("command text: %s", res.Text)
+	}
+	want := user.View{
+		Room:  "комната",
+		Title: "комната",
+		Exits: []user.ExitView{{Name: "коридор", Title: "коридор", To: "коридор"}},
+		Items: []user.ItemView{{Name: "конспекты", Title: "конспекты", Place: "стол"}},
+		Inventory: []user.ItemView{{Name: "рюкзак", Title: "рюкзак", Contents: []user.ItemView{
+			{Name: "ключи", Title: "ключи"},
+		}}},
+		Turn: 4,
+	}
+	if res.State == nil || !reflect.DeepEqual(*res.State, want) {
+		t.Errorf("state\n\tresult:   %+v\n\texpected: %+v", res.State, want)
+	}
+
+	_, res = call(t, api, "GET", petya+"/state", "")
+	if !reflect.DeepEqual(res.Messages, []string{"вася went to hallway"}) {
+		t.Errorf("messages: %q", res.Messages)
+	}
+	if res.State.Title != "kitchen" || res.State.Exits[0].Title != "hallway" || res.State.Exits[0].Name != "коридор" {
+		t.Errorf("en state: %+v", res.State)
+	}
+	if !strings.HasPrefix(res.State.Mission, "you need to pack") {
+		t.Errorf("en mission: %q", res.State.Mission)
+	}
+
+	if code, _ := call(t, api, "DELETE", vasya, ""); code != http.StatusNoContent {
+		t.Errorf("delete: %d", code)
+	}
+	if code, res := call(t, api, "GET", vasya+"/state", ""); code != http.StatusNotFound || res.Error == "" {
+		t.Errorf("deleted session: %d %+v", code, res)
+	}
+	if code, _ := call(t, api, "POST", petya+"/commands", `{"command":`); code != http.StatusBadRequest {
+		t.Errorf("bad json: %d", code)
+	}
+}
+
+func TestAPIIdle(t *testing.T) {
+	g := newTestGame(t)
+	api := NewAPI(g)
+	api.IdleTimeout = 20 * time.Millisecond
+
+	_, res := call(t, api, "POST", "/sessions", `{"name": "вася"}`)
+	vasya := "/sessions/" + res.Session
+	time.Sleep(api.IdleTimeout / 2)
+	if code, _ := call(t, api, "GET", vasya+"/state", ""); code != http.StatusOK {
+		t.Fatalf("active session: %d", code)
+	}
+	time.Sleep(api.IdleTimeout * 2)
+	if code, _ := call(t, api, "GET", vasya+"/state", ""); code != http.StatusNotFound {
+		t.Errorf("idle session: %d", code)
+	}
+	if _, err := g.Join("вася"); err != nil {
+		t.Errorf("idle player is still in game: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package clock
+
+import "sort"
+
+// Pending - событие, которое случится на ходу At
+type Pending struct {
+	ID string `json:"id"`
+	At int    `json:"at"`
+}
+
+// Clock - счётчик ходов мира и очередь запланированных событий
+type Clock struct {
+	Turn    int
+	Pending []Pending
+}
+
+// Schedule планирует событие id через in ходов от текущего
+func (c *Clock) Schedule(id string, in int) {
+	if in < 1 {
+		in = 1
+	}
+	c.Pending = append(c.Pending, Pending{ID: id, At: c.Turn + in})
+	sort.SliceStable(c.Pending, func(i, j int) bool {
+		return c.Pending[i].At < c.Pending[j].At
+	})
+}
+
+// Cancel убирает из очереди все запланированные события id
+func (c *Clock) Cancel(id string) {
+	kept := c.Pending[:0]
+	for _, p := range c.Pending {
+		if p.ID != id {
+			kept = append(kept, p)
+		}
+	}
+	c.Pending = kept
+}
+
+// Tick делает ход и отдаёт события, которым пора случиться, в порядке планирования
+func (c *Clock) Tick() []string {
+	c.Turn++
+	var due []string
+	for len(c.Pending) > 0 && c.Pending[0].At <= c.Turn {
+		due = append(due, c.Pending[0].ID)
+		c.Pending = c.Pending[1:]
+	}
+	return due
+}
@@ -0,0 +1,25 @@
+package clock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClock(t *testing.T) {
+	c := &Clock{}
+	c.Schedule("автобус", 3)
+	c.Schedule("чай", 1)
+	c.Schedule("дверь", 3)
+	c.Schedule("гроза", 5)
+	c.Cancel("гроза")
+
+	want := [][]string{{"чай"}, nil, {"автобус", "дверь"}, nil, nil}
+	for i, w := range want {
+		if got := c.Tick(); !reflect.DeepEqual(got, w) {
+			t.Errorf("turn %d: %q, want %q", i+1, got, w)
+		}
+	}
+	if c.Turn != 5 || len(c.Pending) != 0 {
+		t.Errorf("turn %d, pending %v", c.Turn, c.Pending)
+	}
+}
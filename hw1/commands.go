@@ -0,0 +1,434 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/parser"
+	"github.com/Keniden/vk-homework/game/user"
+)
+
+// Command - глагол игры; Aliases - синонимы и другие формы глагола,
+// En - английские глаголы, первый из них показывается в подсказках на английском,
+// Args - названия аргументов для подсказок, последние Optional из них можно не указывать,
+// Rest - последний аргумент - весь остаток строки без разбора ("сказать кот иди на улицу"),
+// Preps - с каким предлогом команда становится другой: "идти к улице" - это "дойти улица",
+// NoTurn - команда не тратит ход (служебные команды вроде сохранения),
+// NoUndo - команда только смотрит, её не запоминают для "отменить",
+// AfterEnd - команда работает и после того, как игрок выполнил все задания,
+// Admin - команда мастера, для остальных её как будто нет,
+// Frontend - команду выполняет сам фронтенд (консоль, tcp сервер), в игре она есть только для помощи.
+// Описание команды для помощи - сообщение каталога "help.<Name>"
+type Command struct {
+	Name     string
+	Aliases  []string
+	En       []string
+	Args     []string
+	Optional int
+	Rest     bool
+	Preps    map[string]string
+	NoTurn   bool
+	NoUndo   bool
+	AfterEnd bool
+	Admin    bool
+	Frontend bool
+	Run      func(g *Game, gamer *user.User, args []string) string
+}
+
+// names - имя и синонимы команды на языке l
+func (c *Command) names(l *msg.Locale) (string, []string) {
+	if l.Lang == "en" && len(c.En) > 0 {
+		return c.En[0], c.En[1:]
+	}
+	return c.Name, c.Aliases
+}
+
+func (c *Command) Usage(l *msg.Locale) string {
+	usage, _ := c.names(l)
+	for i, arg := range c.Args {
+		arg = l.Sprint("arg." + arg)
+		if i < len(c.Args)-c.Optional {
+			usage += " <" + arg + ">"
+		} else {
+			usage += " [" + arg + "]"
+		}
+	}
+	return usage
+}
+
+// byPrep - в какую команду превращается эта с предлогами preps
+func (c *Command) byPrep(preps []string) *Command {
+	for _, p := range preps {
+		if name, ok := c.Preps[p]; ok {
+			return commands.Lookup(name)
+		}
+	}
+	return c
+}
+
+// joinRest - аргументы команды; у Rest последний аргумент - остаток строки как игрок его написал
+func (c *Command) joinRest(cmd parser.Command) []string {
+	args := cmd.Args
+	if !c.Rest || len(args) < len(c.Args) || len(c.Args) == 0 {
+		return args
+	}
+	last := len(c.Args) - 1
+	return append(args[:last:last], cmd.Tail(last))
+}
+
+// checkArgs возвращает подсказку, если аргументов не столько, сколько нужно
+func (c *Command) checkArgs(l *msg.Locale, args []string) string {
+	switch {
+	case len(args) < len(c.Args)-c.Optional:
+		return l.Sprint("args.few", msg.Raw(c.Usage(l)))
+	case len(args) > len(c.Args):
+		return l.Sprint("args.many", msg.Raw(c.Usage(l)))
+	}
+	return ""
+}
+
+// frontend - имя команды фронтенда, которую набрал игрок: "quit" - это "выход"
+func frontend(line string) string {
+	c := commands.Lookup(strings.ToLower(strings.TrimSpace(line)))
+	if c == nil || !c.Frontend {
+		return ""
+	}
+	return c.Name
+}
+
+type Registry struct {
+	commands []*Command
+	byName   map[string]*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]*Command)}
+}
+
+// Register добавляет команду; повтор имени или синонима - ошибка программиста
+func (r *Registry) Register(c *Command) {
+	for _, name := range append(append([]string{c.Name}, c.Aliases...), c.En...) {
+		if _, ok := r.byName[name]; ok {
+			panic(fmt.Sprintf("command %q registered twice", name))
+		}
+		r.byName[name] = c
+	}
+	r.commands = append(r.commands, c)
+}
+
+func (r *Registry) Lookup(name string) *Command {
+	return r.byName[name]
+}
+
+// Help - подсказка по командам; команды мастера видны только admin
+func (r *Registry) Help(l *msg.Locale, admin bool) string {
+	lines := make([]string, 0, len(r.commands)+1)
+	lines = append(lines, l.Sprint("help"))
+	for _, c := range r.commands {
+		if c.Admin && !admin {
+			continue
+		}
+		line := c.Usage(l)
+		if _, aliases := c.names(l); len(aliases) > 0 {
+			line += " (" + strings.Join(aliases, ", ") + ")"
+		}
+		lines = append(lines, line+" - "+l.Sprint("help."+c.Name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var commands = NewRegistry()
+
+func init() {
+	commands.Register(&Command{
+		Name:     "осмотреться",
+		Aliases:  []string{"оглядеться", "осмотрись", "смотреть"},
+		En:       []string{"look", "l"},
+		AfterEnd: true,
+		NoUndo:   true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Look()
+		},
+	})
+	commands.Register(&Command{
+		Name:    "идти",
+		Aliases: []string{"пойти", "иди", "пройти", "зайти"},
+		En:      []string{"go", "walk"},
+		Args:    []string{"куда"},
+		Preps:   map[string]string{"к": "дойти", "ко": "дойти", "to": "дойти"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.GoTo(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "дойти",
+		Aliases: []string{"добраться"},
+		En:      []string{"travel"},
+		Args:    []string{"куда"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Travel(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "надеть",
+		Aliases: []string{"одеть", "надень"},
+		En:      []string{"wear"},
+		Args:    []string{"что"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Wear(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "взять",
+		Aliases: []string{"подобрать", "возьми", "забрать"},
+		En:      []string{"take", "get"},
+		Args:    []string{"что"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Take(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "положить",
+		Aliases: []string{"положи", "сложить", "убрать"},
+		En:      []string{"put"},
+		Args:    []string{"что", "куда"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Put(args[0], args[1])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "инвентарь",
+		Aliases:  []string{"и", "вещи"},
+		En:       []string{"inventory", "inv"},
+		AfterEnd: true,
+		NoUndo:   true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Inventory()
+		},
+	})
+	commands.Register(&Command{
+		Name:    "выложить",
+		Aliases: []string{"выложи", "бросить", "оставить"},
+		En:      []string{"drop"},
+		Args:    []string{"что"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Drop(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "осмотреть",
+		Aliases: []string{"рассмотреть", "осмотри"},
+		En:      []string{"examine", "x"},
+		Args:    []string{"что"},
+		NoUndo:  true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Examine(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "применить",
+		Aliases: []string{"использовать", "примени"},
+		En:      []string{"use"},
+		Args:    []string{"что", "к чему"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Use(args[0], args[1])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "поговорить",
+		Aliases: []string{"заговорить", "говорить"},
+		En:      []string{"talk"},
+		Args:    []string{"с кем"},
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Talk(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:    "сказать",
+		Aliases: []string{"ответить", "скажи"},
+		En:      []string{"say", "answer"},
+		Args:    []string{"кому", "фраза"},
+		Rest:    true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Say(args[0], args[1])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "сохранить",
+		En:       []string{"save"},
+		Args:     []string{"слот"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.save(gamer, args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "загрузить",
+		En:       []string{"load"},
+		Args:     []string{"слот"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.load(gamer, args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "отменить",
+		En:       []string{"undo"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.undo(gamer)
+		},
+	})
+	commands.Register(&Command{
+		Name:     "отметка",
+		En:       []string{"checkpoint", "mark"},
+		Args:     []string{"имя"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.mark(gamer, args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "вернуться",
+		En:       []string{"back", "return"},
+		Args:     []string{"имя"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.back(gamer, args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "время",
+		Aliases:  []string{"ход"},
+		En:       []string{"time"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.T("time", g.World.Clock.Turn)
+		},
+	})
+	commands.Register(&Command{
+		Name:     "язык",
+		En:       []string{"language", "lang"},
+		Args:     []string{"язык"},
+		Optional: 1,
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			lang := ""
+			if len(args) > 0 {
+				lang = args[0]
+			}
+			answer, _ := g.setLang(gamer, lang)
+			return answer
+		},
+	})
+	commands.Register(&Command{
+		Name:     "помощь",
+		Aliases:  []string{"команды"},
+		En:       []string{"help"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return commands.Help(gamer.Lang, g.admin(gamer))
+		},
+	})
+	commands.Register(&Command{
+		Name:     quitCommand,
+		En:       []string{"quit", "exit"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Frontend: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.T("frontend")
+		},
+	})
+	commands.Register(&Command{
+		Name:     historyCommand,
+		En:       []string{"history"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Frontend: true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.T("frontend")
+		},
+	})
+
+	registerAdmin()
+}
+
+// команды мастера работают без хода и после конца игры
+func registerAdmin() {
+	commands.Register(&Command{
+		Name:     "телепорт",
+		En:       []string{"teleport", "goto"},
+		Args:     []string{"куда"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Teleport(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "создать",
+		En:       []string{"spawn"},
+		Args:     []string{"что"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Spawn(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "уничтожить",
+		En:       []string{"destroy"},
+		Args:     []string{"что"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Destroy(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "отпереть",
+		En:       []string{"unlock"},
+		Args:     []string{"дверь"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return gamer.Unlock(args[0])
+		},
+	})
+	commands.Register(&Command{
+		Name:     "состояние",
+		En:       []string{"dump"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		NoUndo:   true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.dump(gamer)
+		},
+	})
+	commands.Register(&Command{
+		Name:     "комнаты",
+		En:       []string{"rooms"},
+		NoTurn:   true,
+		AfterEnd: true,
+		Admin:    true,
+		NoUndo:   true,
+		Run: func(g *Game, gamer *user.User, args []string) string {
+			return g.rooms(gamer)
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Kind - что случилось в мире
+type Kind string
+
+const (
+	Join   Kind = "join"   // игрок вошёл в игру
+	Leave  Kind = "leave"  // игрок ушёл из игры
+	Move   Kind = "move"   // игрок перешёл из From в Room
+	Take   Kind = "take"   // игрок взял Item из комнаты
+	Drop   Kind = "drop"   // игрок выложил Item в комнату
+	Wear   Kind = "wear"   // игрок надел Item
+	Put    Kind = "put"    // игрок положил Item в Target
+	Use    Kind = "use"    // игрок применил Item к Target
+	Give   Kind = "give"   // игрок получил Item в подарок
+	Hand   Kind = "hand"   // игрок отдал Item персонажу Target
+	Unlock Kind = "unlock" // дверь Door отперли
+	Lock   Kind = "lock"   // дверь Door заперли
+	Spawn  Kind = "spawn"  // в комнате появился Item
+	Remove Kind = "remove" // Item пропал из комнаты или у игрока
+	Set    Kind = "set"    // в комнате поднят флаг Target
+	Unset  Kind = "unset"  // в комнате снят флаг Target
+	Quest  Kind = "quest"  // игрок выполнил задание Target
+	// мир целиком вернулся в прошлое, отдельных событий об этом нет
+	Undo Kind = "undo" // игрок отменил свою команду Target
+	Back Kind = "back" // игрок вернул мир к своей отметке Target
+	Load Kind = "load" // игрок загрузил сохранение Target
+)
+
+// Event - изменение мира; Player пустой, если мир изменился сам, по часам
+type Event struct {
+	Kind   Kind   `json:"kind"`
+	Turn   int    `json:"turn"`
+	Player string `json:"player,omitempty"`
+	Room   string `json:"room,omitempty"`
+	From   string `json:"from,omitempty"`
+	Item   string `json:"item,omitempty"`
+	Target string `json:"target,omitempty"`
+	Door   string `json:"door,omitempty"`
+}
+
+// Observer узнаёт о событиях сразу, как они случились,
+// пока мир заблокирован - долгую работу лучше уносить в свою горутину
+type Observer interface {
+	Observe(e Event)
+}
+
+// Func - функция как Observer
+type Func func(e Event)
+
+func (f Func) Observe(e Event) {
+	f(e)
+}
+
+// Bus раздаёт события наблюдателям в порядке подписки, нулевое значение готово к работе
+type Bus struct {
+	observers []Observer
+}
+
+func (b *Bus) Subscribe(o Observer) {
+	b.observers = append(b.observers, o)
+}
+
+func (b *Bus) Emit(e Event) {
+	for _, o := range b.observers {
+		o.Observe(e)
+	}
+}
+
+// JSONL пишет события в w по одному json в строке, например в журнал аудита.
+// Err - первая ошибка записи, после неё журнал молчит
+type JSONL struct {
+	Err error
+
+	enc *json.Encoder
+}
+
+func NewJSONL(w io.Writer) *JSONL {
+	return &JSONL{enc: json.NewEncoder(w)}
+}
+
+func (j *JSONL) Observe(e Event) {
+	if j.Err == nil {
+		j.Err = j.enc.Encode(e)
+	}
+}
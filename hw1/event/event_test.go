@@ -0,0 +1,30 @@
+package event
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	var b Bus
+	b.Emit(Event{Kind: Join, Player: "вася"})
+
+	var got []Kind
+	var buf bytes.Buffer
+	b.Subscribe(Func(func(e Event) { got = append(got, e.Kind) }))
+	b.Subscribe(NewJSONL(&buf))
+
+	b.Emit(Event{Kind: Move, Turn: 1, Player: "вася", Room: "коридор", From: "кухня"})
+	b.Emit(Event{Kind: Unlock, Turn: 2, Room: "коридор", Door: "входная"})
+
+	if want := []Kind{Move, Unlock}; !reflect.DeepEqual(got, want) {
+		t.Errorf("kinds %q, want %q", got, want)
+	}
+	want := `{"kind":"move","turn":1,"player":"вася","room":"коридор","from":"кухня"}
+{"kind":"unlock","turn":2,"room":"коридор","door":"входная"}
+`
+	if buf.String() != want {
+		t.Errorf("jsonl\n\tresult:   %s\n\texpected: %s", buf.String(), want)
+	}
+}
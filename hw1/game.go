@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/parser"
+	"github.com/Keniden/vk-homework/game/state"
+	"github.com/Keniden/vk-homework/game/user"
+	"github.com/Keniden/vk-homework/game/world"
+)
+
+// Game - общий мир и игроки в нём, методы можно звать из разных горутин
+type Game struct {
+	World   *world.World
+	Players map[string]*user.User
+	SaveDir string
+	// Lang - язык, с которым игроки входят в игру, на нём же ответы тем, кто ещё не вошёл
+	Lang *msg.Locale
+	// Debug - режим отладки, команды мастера доступны всем
+	Debug bool
+	// Admins - имена игроков, которые входят в игру мастерами
+	Admins map[string]bool
+
+	parser *parser.Parser
+	// history - снимки мира перед последними ходами, по ним работает "отменить"
+	history []move
+	// checkpoints - отметки игроков, по имени игрока и имени отметки
+	checkpoints map[string]map[string]checkpoint
+	// changes - счётчик изменений мира, lastChange - номер последнего изменения каждого игрока
+	changes    int
+	lastChange map[string]int
+
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+func NewGame(w *world.World) *Game {
+	g := &Game{
+		World:       w,
+		Players:     make(map[string]*user.User),
+		SaveDir:     "saves",
+		parser:      parser.New(w.Names()),
+		Lang:        w.Locale("ru"),
+		waiters:     make(map[string]chan struct{}),
+		checkpoints: make(map[string]map[string]checkpoint),
+		lastChange:  make(map[string]int),
+	}
+	// названия можно писать и в переводе: "take keys" - это "взять ключи"
+	names := make(map[string]bool)
+	for _, n := range w.Names() {
+		names[n] = true
+	}
+	for _, l := range w.Locales {
+		for word, tr := range l.Words {
+			if names[word] {
+				g.parser.Alias(tr, word)
+			}
+		}
+	}
+	return g
+}
+
+// Text - сообщение каталога на языке игрока name
+func (g *Game) Text(name, id string, args ...any) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.locale(name).Sprint(id, args...)
+}
+
+func (g *Game) locale(name string) *msg.Locale {
+	if u, ok := g.Players[name]; ok {
+		return u.Lang
+	}
+	return g.Lang
+}
+
+func (g *Game) Join(name string) (*user.User, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.wake()
+
+	if name == "" {
+		return nil, errors.New(g.Lang.Sprint("join.empty"))
+	}
+	if _, ok := g.Players[name]; ok {
+		return nil, errors.New(g.Lang.Sprint("join.taken", name))
+	}
+	u := user.NewUser(name, g.World)
+	u.Lang = g.Lang
+	u.Admin = g.Admins[name]
+	u.InPlace.Announce(u, msg.T("join.say", name))
+	g.Players[name] = u
+	return u, nil
+}
+
+func (g *Game) Leave(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.wake()
+
+	u, ok := g.Players[name]
+	if !ok {
+		return
+	}
+	u.Quit()
+	delete(g.Players, name)
+	delete(g.waiters, name)
+	delete(g.checkpoints, name)
+}
+
+// Subscribe возвращает канал, в который приходит сигнал, когда у игрока появились сообщения
+func (g *Game) Subscribe(name string) <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	g.waiters[name] = ch
+	return ch
+}
+
+// Messages забирает сообщения, накопившиеся у игрока
+func (g *Game) Messages(name string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	u, ok := g.Players[name]
+	if !ok {
+		return nil
+	}
+	return u.Messages()
+}
+
+// View - состояние игрока name для клиентов
+func (g *Game) View(name string) (user.View, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	u, ok := g.Players[name]
+	if !ok {
+		return user.View{}, false
+	}
+	return u.View(), true
+}
+
+func (g *Game) wake() {
+	for name, ch := range g.waiters {
+		u, ok := g.Players[name]
+		if !ok || len(u.Inbox) == 0 {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Look - описание комнаты для игрока name без траты хода, например приветствие после входа
+func (g *Game) Look(name string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.wake()
+
+	gamer, ok := g.Players[name]
+	if !ok {
+		return g.Lang.Sprint("no_player", name)
+	}
+	return gamer.Look()
+}
+
+// Handle выполняет команду от имени игрока name
+func (g *Game) Handle(name, command string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.wake()
+
+	gamer, ok := g.Players[name]
+	if !ok {
+		return g.Lang.Sprint("no_player", name)
+	}
+
+	cmd, err := g.parser.Parse(command)
+	switch {
+	case errors.Is(err, parser.ErrQuote):
+		return gamer.T("quote")
+	case err != nil:
+		return err.Error()
+	}
+	c := commands.Lookup(cmd.Verb)
+	if c == nil || c.Admin && !g.admin(gamer) {
+		return gamer.T("unknown")
+	}
+	c = c.byPrep(cmd.Preps)
+	if gamer.Finished() && !c.AfterEnd {
+		return gamer.T("over")
+	}
+	args := c.joinRest(cmd)
+	if hint := c.checkArgs(gamer.Lang, args); hint != "" {
+		return hint
+	}
+	// команды мастера хода не тратят, но мир меняют - их тоже можно отменить
+	var before *state.Snapshot
+	if (!c.NoTurn || c.Admin) && !c.NoUndo {
+		before = state.Capture(g.World, g.players())
+	}
+	answer := c.Run(g, gamer, args)
+	recorded := before != nil && g.remember(gamer, command, before)
+	if !c.NoTurn {
+		g.tick()
+		if !recorded {
+			g.pass(gamer, command)
+		}
+	}
+	gamer.CheckQuests()
+	return answer
+}
+
+// tick - ход мира: случившиеся события применяются, а их сообщения слышат игроки рядом
+func (g *Game) tick() {
+	for _, ev := range g.World.Tick() {
+		if ev.Message == "" {
+			continue
+		}
+		if r := g.World.Room(ev.Room); r != nil {
+			r.Announce(nil, msg.Raw(ev.Message))
+			continue
+		}
+		for _, u := range g.Players {
+			u.Notify(msg.Raw(ev.Message))
+		}
+	}
+}
+
+func (g *Game) slotPath(slot string) (string, bool) {
+	if slot == "" || slot != filepath.Base(slot) || strings.HasPrefix(slot, ".") {
+		return "", false
+	}
+	return filepath.Join(g.SaveDir, slot+".json"), true
+}
+
+// players - игроки по имени, чтобы снимки одного и того же мира совпадали
+func (g *Game) players() []*user.User {
+	res := make([]*user.User, 0, len(g.Players))
+	for _, u := range g.Players {
+		res = append(res, u)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func (g *Game) save(gamer *user.User, slot string) string {
+	path, ok := g.slotPath(slot)
+	if !ok {
+		return gamer.T("save.bad", slot)
+	}
+	if err := os.MkdirAll(g.SaveDir, 0o755); err != nil {
+		return gamer.T("save.fail", err)
+	}
+	if err := state.Save(path, state.Capture(g.World, g.players())); err != nil {
+		return gamer.T("save.fail", err)
+	}
+	return gamer.T("save.done", slot)
+}
+
+func (g *Game) load(gamer *user.User, slot string) string {
+	path, ok := g.slotPath(slot)
+	if !ok {
+		return gamer.T("save.bad", slot)
+	}
+	snap, err := state.Load(path)
+	if os.IsNotExist(err) {
+		return gamer.T("load.none", slot)
+	}
+	if err != nil {
+		return gamer.T("load.fail", err)
+	}
+	if err := snap.Restore(g.World, g.players()); err != nil {
+		return gamer.T("load.fail", err)
+	}
+	g.history = nil
+	g.changed(gamer)
+	g.World.Emit(event.Event{Kind: event.Load, Player: gamer.Name, Room: gamer.InPlace.ID, Target: slot})
+	g.notifyOthers(gamer, msg.T("load.say", gamer.Name, slot))
+	return gamer.T("load.done", slot)
+}
+
+// notifyOthers - сообщение всем игрокам, кроме gamer
+func (g *Game) notifyOthers(gamer *user.User, t msg.Text) {
+	for _, u := range g.Players {
+		if u != gamer {
+			u.Notify(t)
+		}
+	}
+}
+
+// SetLang переключает язык игрока name
+func (g *Game) SetLang(name, lang string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	u, ok := g.Players[name]
+	if !ok {
+		return errors.New(g.Lang.Sprint("no_player", name))
+	}
+	if answer, ok := g.setLang(u, lang); !ok {
+		return errors.New(answer)
+	}
+	return nil
+}
+
+// setLang - команда "язык": без аргумента показывает текущий язык, с ним - переключает
+func (g *Game) setLang(gamer *user.User, lang string) (string, bool) {
+	if lang == "" {
+		return gamer.T("lang.set", gamer.Lang.Lang), true
+	}
+	l := g.World.Locale(lang)
+	if l == nil {
+		return gamer.T("lang.none", lang, strings.Join(msg.Langs(), ", ")), false
+	}
+	gamer.Lang = l
+	return gamer.T("lang.set", l.Lang), true
+}
@@ -0,0 +1,977 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/world"
+)
+
+func newTestGame(t *testing.T, names ...string) *Game {
+	t.Helper()
+	w, err := loadWorld()
+	if err != nil {
+		t.Fatalf("load world: %v", err)
+	}
+	g := NewGame(w)
+	for _, name := range names {
+		if _, err := g.Join(name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+	return g
+}
+
+func TestGameMultiplayer(t *testing.T) {
+	g := newTestGame(t, "вася", "петя")
+
+	if _, err := g.Join("вася"); err == nil {
+		t.Fatalf("expected error for duplicate player")
+	}
+
+	steps := []struct {
+		player  string
+		command string
+		answer  string
+	}{
+		{"вася", "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. здесь также: петя. можно пройти - коридор"},
+		{"вася", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"вася", "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"вася", "надеть рюкзак", "вы надели: рюкзак"},
+		{"вася", "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"петя", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"петя", "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"петя", "осмотреться", "на столе: конспекты. здесь также: вася. можно пройти - коридор"},
+		{"петя", "взять ключи", "некуда класть"},
+		{"никто", "осмотреться", "нет игрока никто"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle(s.player, s.command); answer != s.answer {
+			t.Errorf("%s: %s\n\tresult:   %s\n\texpected: %s", s.player, s.command, answer, s.answer)
+		}
+	}
+
+	msgs := g.Players["вася"].Messages()
+	want := []string{"петя вошёл в игру", "петя пришёл"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("вася messages = %q, want %q", msgs, want)
+	}
+
+	g.Leave("вася")
+	if answer := g.Handle("петя", "осмотреться"); answer != "на столе: конспекты. можно пройти - коридор" {
+		t.Fatalf("after leave: %s", answer)
+	}
+	msgs = g.Players["петя"].Messages()
+	want = []string{"вася ушёл в коридор", "вася ушёл из игры"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("петя messages = %q, want %q", msgs, want)
+	}
+}
+
+func TestGameSaveLoad(t *testing.T) {
+	g := newTestGame(t, "вася")
+	g.SaveDir = t.TempDir()
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"загрузить первый", "нет сохранения первый"},
+		{"сохранить ../первый", "неправильное имя сохранения - ../первый"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"сохранить первый", "игра сохранена: первый"},
+		{"взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"применить ключи дверь", "дверь открыта"},
+		{"загрузить первый", "игра загружена: первый"},
+		{"осмотреться", "на столе: конспекты. можно пройти - коридор"},
+		{"применить конспекты дверь", "нет предмета в инвентаре - конспекты"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти улица", "дверь закрыта"},
+		{"применить ключи дверь", "дверь открыта"},
+		{"идти улица", "на улице весна. можно пройти - домой"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameUseRules(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "комната", "go": "комната. ", "items": ["ключ", "чайник", {"name": "рюкзак", "capacity": 10, "wearable": true, "place": "на стуле"}]}
+	],
+	"rules": [
+		{"item": "ключ", "target": "шкаф", "if": {"no_flag": "шкаф открыт"}, "effects": [{"set": "шкаф открыт"}, {"spawn": "куртка"}], "message": "шкаф открыт"},
+		{"item": "чайник", "target": "чай", "effects": [{"give": "чай"}], "consume": true, "message": "чай заварен"}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"взять ключ", "предмет добавлен в инвентарь: ключ"},
+		{"взять чайник", "предмет добавлен в инвентарь: чайник"},
+		{"применить ключ шкаф", "шкаф открыт"},
+		{"применить ключ шкаф", "не к чему применить"},
+		{"осмотреться", "на столе: куртка. можно пройти - некуда"},
+		{"применить чайник чай", "чай заварен"},
+		{"применить чайник чай", "нет предмета в инвентаре - чайник"},
+		{"применить чай шкаф", "не к чему применить"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameTriggers(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "прихожая", "go": "прихожая. ", "items": ["зонт", {"name": "рюкзак", "capacity": 10, "wearable": true}], "exits": ["кладовка"]},
+		{"name": "кладовка", "go": "кладовка. ", "items": ["ведро"], "exits": ["прихожая"]}
+	],
+	"triggers": [
+		{"room": "прихожая", "on": "leave", "if": {"has": "зонт"}, "refuse": "зонт не пролезет в дверь"},
+		{"room": "кладовка", "on": "enter", "if": {"no_flag": "свет"}, "desc": "темно. ", "say": "щёлк!", "effects": [{"set": "свет"}]},
+		{"room": "кладовка", "on": "take", "if": {"item": "ведро"}, "say": "ведро звякнуло."},
+		{"room": "прихожая", "on": "use", "if": {"target": "зеркало"}, "refuse": "в зеркале вы"},
+		{"room": "кладовка", "on": "look", "if": {"has": "ведро"}, "mission": "отнести ведро"}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"взять зонт", "предмет добавлен в инвентарь: зонт"},
+		{"применить зонт зеркало", "в зеркале вы"},
+		{"идти кладовка", "зонт не пролезет в дверь"},
+		{"применить зонт лампа", "не к чему применить"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+
+	g.Players["вася"].Worn[0].Contents = nil
+	steps = []struct {
+		command string
+		answer  string
+	}{
+		{"идти кладовка", "темно. можно пройти - прихожая щёлк!"},
+		{"идти прихожая", "прихожая. можно пройти - кладовка"},
+		{"идти кладовка", "кладовка. можно пройти - прихожая"},
+		{"взять ведро", "предмет добавлен в инвентарь: ведро ведро звякнуло."},
+		{"осмотреться", "на столе: ничего, отнести ведро. можно пройти - прихожая"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+	if v, _ := g.View("вася"); v.Mission != "отнести ведро" {
+		t.Errorf("view mission: %q", v.Mission)
+	}
+}
+
+func TestGameContainers(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "склад", "go": "склад. ", "items": [
+			{"name": "сумка", "capacity": 3, "wearable": true, "place": "на крючке"},
+			{"name": "кошелёк", "capacity": 1, "contents": ["монета"]},
+			{"name": "гиря", "weight": 5},
+			"книга",
+			"тетрадь",
+			"ручка",
+			"шапка"
+		]}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"осмотреться", "на столе: кошелёк, гиря, книга, тетрадь, ручка, шапка, на крючке: сумка. можно пройти - некуда"},
+		{"взять книга", "некуда класть"},
+		{"надеть гиря", "гиря нельзя надеть"},
+		{"надеть сумка", "вы надели: сумка"},
+		{"взять гиря", "некуда класть"},
+		{"взять кошелёк", "предмет добавлен в инвентарь: кошелёк"},
+		{"применить монета гиря", "не к чему применить"},
+		{"положить книга в кошелёк", "книга не помещается в кошелёк"},
+		{"положить сумка в кошелёк", "сумка не помещается в кошелёк"},
+		{"взять книга", "предмет добавлен в инвентарь: книга"},
+		{"взять тетрадь", "некуда класть"},
+		{"положить тетрадь в гиря", "в гиря ничего не положить"},
+		{"положить ручка в шапка", "в шапка ничего не положить"},
+		{"положить зонт в сумка", "нет такого - зонт"},
+		{"осмотреться", "на столе: гиря, тетрадь, ручка, шапка. можно пройти - некуда"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameSurfaces(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "кладовка", "go": "кладовка. ",
+			"surfaces": [{"id": "полка", "name": "на полке", "always": true}, {"id": "пол", "name": "на полу"}],
+			"items": [
+				{"name": "банка", "place": "полка"},
+				{"name": "сумка", "capacity": 3, "wearable": true, "place": "пол"},
+				{"name": "коробка", "place": "пол"}
+			]}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"осмотреться", "на полке: банка, на полу: сумка, коробка. можно пройти - некуда"},
+		{"надеть сумка", "вы надели: сумка"},
+		{"взять банка", "предмет добавлен в инвентарь: банка"},
+		{"осмотреться", "на полке: ничего, на полу: коробка. можно пройти - некуда"},
+		{"взять коробка", "предмет добавлен в инвентарь: коробка"},
+		{"осмотреться", "на полке: ничего. можно пройти - некуда"},
+		// выложенное кладётся на первое место комнаты
+		{"выложить коробка", "вы выложили: коробка"},
+		{"осмотреться", "на полке: коробка. можно пройти - некуда"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameInventory(t *testing.T) {
+	g := newTestGame(t, "вася")
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"инвентарь", "инвентарь пуст"},
+		{"осмотреть чай", "чай уже остыл"},
+		{"осмотреть ключи", "нет такого"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"осмотреть рюкзак", "старый рюкзак. внутри пусто"},
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{"инвентарь", "на вас: рюкзак (ключи, конспекты)"},
+		{"осмотреть рюкзак", "старый рюкзак. внутри: ключи, конспекты"},
+		{"осмотреть конспекты", "конспекты лекций, почти все"},
+		{"выложить телефон", "нет предмета в инвентаре - телефон"},
+		{"выложить конспекты", "вы выложили: конспекты"},
+		{"осмотреться", "на столе: конспекты. можно пройти - коридор"},
+		{"выложить рюкзак", "вы выложили: рюкзак"},
+		{"инвентарь", "инвентарь пуст"},
+		{"осмотреться", "на столе: конспекты, рюкзак. можно пройти - коридор"},
+		{"взять конспекты", "некуда класть"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameCommandRegistry(t *testing.T) {
+	g := newTestGame(t, "вася")
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"идти", "не хватает аргументов: идти <куда>"},
+		{"применить ключи", "не хватает аргументов: применить <что> <к чему>"},
+		{"осмотреться вокруг", "лишние аргументы: осмотреться"},
+		{"", "неизвестная команда"},
+		{"положить чай в", "не хватает аргументов: положить <что> <куда>"},
+		{"положить чай кухня", "нет такого - кухня"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%q\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+
+	help := g.Handle("вася", "помощь")
+	for _, line := range []string{"идти <куда> (пойти, иди, пройти, зайти) - перейти в соседнюю комнату", "помощь (команды) - список команд"} {
+		if !strings.Contains(help, line) {
+			t.Errorf("help has no %q:\n%s", line, help)
+		}
+	}
+	if g.Handle("вася", "команды") != help {
+		t.Errorf("alias should give the same help")
+	}
+}
+
+func TestRegistryOptionalArgs(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&Command{Name: "сказать", Args: []string{"кому", "что"}, Optional: 1})
+	c := r.Lookup("сказать")
+	ru, _ := msg.New("ru", nil, nil)
+	if got := c.Usage(ru); got != "сказать <кому> [что]" {
+		t.Fatalf("usage = %q", got)
+	}
+	if hint := c.checkArgs(ru, []string{"пете"}); hint != "" {
+		t.Fatalf("optional arg should be allowed to skip: %s", hint)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on duplicate command")
+		}
+	}()
+	r.Register(&Command{Name: "говорить", Aliases: []string{"сказать"}})
+}
+
+func TestGameNaturalCommands(t *testing.T) {
+	g := newTestGame(t, "вася")
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"пойти  в коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"Идти в комнату", "ты в своей комнате. можно пройти - коридор"},
+		{"надень рюкзак", "вы надели: рюкзак"},
+		{"подобрать ключи", "предмет добавлен в инвентарь: ключи"},
+		{"выложить конспекты", "нет предмета в инвентаре - конспекты"},
+		{"положить конспекты в рюкзак", "вы положили конспекты в рюкзак"},
+		{"осмотреть «рюкзак»", "старый рюкзак. внутри: ключи, конспекты"},
+		{"идти к коридору", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"применить ключи к двери", "дверь открыта"},
+		{"идти на улицу", "на улице весна. можно пройти - домой"},
+		{"взять \"старый зонт", "не закрыта кавычка"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameQuest(t *testing.T) {
+	g := newTestGame(t, "вася", "петя")
+	g.SaveDir = t.TempDir()
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+		// рюкзак надет, но конспекты ещё не взяты
+		{"осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. здесь также: петя. можно пройти - коридор"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"идти кухня", "кухня, ничего интересного. можно пройти - коридор"},
+		{"осмотреться", "ты находишься на кухне, на столе: чай, надо идти в универ. здесь также: петя. можно пройти - коридор"},
+		{"сохранить кухня", "игра сохранена: кухня"},
+		{"идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"применить ключи дверь", "дверь открыта"},
+		{"идти улица", "на улице весна. можно пройти - домой"},
+		{"идти домой", "игра окончена"},
+		{"инвентарь", "на вас: рюкзак (ключи, конспекты)"},
+		{"загрузить кухня", "игра загружена: кухня"},
+		{"осмотреться", "ты находишься на кухне, на столе: чай, надо идти в универ. здесь также: петя. можно пройти - коридор"},
+	}
+	g.Messages("вася")
+	var messages []string
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+		messages = append(messages, g.Messages("вася")...)
+	}
+	want := []string{"рюкзак собран, ты на улице - можно идти в универ. задание выполнено!"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+
+	// у пети своё задание
+	if answer := g.Handle("петя", "осмотреться"); answer != "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. здесь также: вася. можно пройти - коридор" {
+		t.Errorf("петя: %s", answer)
+	}
+}
+
+func TestGameNPC(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{
+			"name": "кухня", "look": "кухня, ", "go": "кухня. ",
+			"items": ["колбаса", {"name": "сумка", "capacity": 10, "wearable": true}],
+			"npcs": [{
+				"name": "кот", "desc": "рыжий кот",
+				"dialogue": [
+					{"id": "привет", "text": "мяу?", "choices": [
+						{"say": "погладить", "reply": "кот мурлычет.", "next": "привет"},
+						{"say": "иди на улицу", "reply": "кот зевает."},
+						{"say": "угостить", "take": "колбаса", "effects": [{"give": "ключ"}, {"set": "кот сыт"}], "reply": "кот отдаёт ключ.", "next": "сытый"},
+						{"say": "пока"}
+					]},
+					{"id": "сытый", "text": "мрр", "choices": [
+						{"say": "ещё колбасы", "if": {"no_flag": "кот сыт"}}
+					]}
+				]
+			}]
+		}
+	],
+	"locales": {"en": {"words": {"погладить": "pet", "иди на улицу": "go outside"}}}
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"осмотреться", "кухня, на столе: колбаса, сумка. здесь также: кот. можно пройти - некуда"},
+		{"осмотреть кота", "рыжий кот"},
+		{"поговорить с котом", "кот: мяу? можно ответить - погладить, иди на улицу, пока"},
+		{"сказать коту угостить", "кот не понимает"},
+		// фраза сравнивается так, как её написал игрок: "на" и "улицу" не разбираются
+		{"сказать коту иди на улицу", "кот зевает."},
+		{"поговорить с котом", "кот: мяу? можно ответить - погладить, иди на улицу, пока"},
+		{"сказать коту погладить", "кот мурлычет. кот: мяу? можно ответить - погладить, иди на улицу, пока"},
+		{"надеть сумка", "вы надели: сумка"},
+		{"взять колбаса", "предмет добавлен в инвентарь: колбаса"},
+		{"поговорить с котом", "кот: мяу? можно ответить - погладить, иди на улицу, угостить, пока"},
+		{"сказать коту 3", "кот отдаёт ключ. кот: мрр"},
+		// отмена возвращает и разговор на реплику, где он был
+		{"отменить", "отменено: сказать коту 3"},
+		{"сказать коту угостить", "кот отдаёт ключ. кот: мрр"},
+		{"сказать коту ещё колбасы", "кот не понимает"},
+		{"инвентарь", "на вас: сумка (ключ)"},
+		{"сказать пёс привет", "здесь нет пёс"},
+		{"сказать кот пока", "кот не понимает"},
+		{"поговорить с котом", "кот: мяу? можно ответить - погладить, иди на улицу, пока"},
+		{"сказать кот пока", "кот молчит"},
+		// ответы показываются и узнаются на языке игрока
+		{"lang en", "language: en"},
+		{"talk to кот", "кот: мяу? you can answer - pet, go outside, пока"},
+		{"say кот go outside", "кот зевает."},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestDefaultWorldCheck(t *testing.T) {
+	w, err := loadWorld()
+	if err != nil {
+		t.Fatalf("load world: %v", err)
+	}
+	for _, p := range w.Check() {
+		if p.Error {
+			t.Errorf("world.json: %s", p)
+		}
+	}
+}
+
+func TestGameTravel(t *testing.T) {
+	g := newTestGame(t, "вася")
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"идти комната", "нет пути в комната"},
+		{"идти к комнате", "ничего интересного. можно пройти - кухня, комната, улица\nты в своей комнате. можно пройти - коридор"},
+		{"дойти до комнаты", "вы уже здесь - комната"},
+		{"надеть рюкзак", "вы надели: рюкзак"},
+		{"взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"идти к улице", "ничего интересного. можно пройти - кухня, комната, улица\nдверь закрыта"},
+		{"применить ключи дверь", "дверь открыта"},
+		{"идти к кухне", "кухня, ничего интересного. можно пройти - коридор"},
+		{"добраться до улицы", "ничего интересного. можно пройти - кухня, комната, улица\nна улице весна. можно пройти - домой"},
+		{"идти к чердаку", "нет пути в чердаку"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+func TestGameTravelQuest(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "дом", "go": "дом. ", "exits": [{"to": "мост", "two_way": true}]},
+		{"name": "мост", "go": "мост. ", "exits": [{"to": "город", "two_way": true}]},
+		{"name": "город", "go": "город. "}
+	],
+	"quests": [{
+		"id": "мост", "intro": "надо", "ordered": true,
+		"objectives": [{"id": "мост", "text": "пройти по мосту", "if": {"room": "мост"}}],
+		"win": "мост пройден"
+	}]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if answer := g.Handle("вася", "дойти город"); answer != "мост. можно пройти - дом, город\nгород. можно пройти - мост" {
+		t.Errorf("travel: %s", answer)
+	}
+	if msgs := g.Messages("вася"); !reflect.DeepEqual(msgs, []string{"мост пройден"}) {
+		t.Errorf("messages: %q", msgs)
+	}
+}
+
+func TestGameClock(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "коридор", "go": "коридор. ", "items": ["ключ", {"name": "сумка", "capacity": 10, "wearable": true}], "exits": [{"to": "улица", "door": "дверь", "two_way": true, "back_label": "домой"}]},
+		{"name": "улица", "go": "улица. ", "items": ["автобус"]}
+	],
+	"doors": [{"id": "дверь", "name": "дверь", "locked": true, "key": "ключ"}],
+	"events": [
+		{"id": "автобус", "at": 5, "room": "улица", "message": "автобус уехал", "effects": [{"remove": "автобус"}]},
+		{"id": "захлопнулась", "room": "коридор", "message": "дверь захлопнулась", "effects": [{"lock": "дверь"}]},
+		{"id": "часы", "every": 3, "message": "часы пробили"}
+	],
+	"rules": [
+		{"item": "ключ", "target": "дверь", "effects": [{"unlock": "дверь"}, {"schedule": "захлопнулась", "in": 2}, {"schedule": "часы", "in": 3}], "message": "дверь открыта, но скоро захлопнется"}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	g.SaveDir = t.TempDir()
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	steps := []struct {
+		command  string
+		answer   string
+		messages []string
+	}{
+		{"надеть сумка", "вы надели: сумка", nil},
+		{"взять ключ", "предмет добавлен в инвентарь: ключ", nil},
+		{"помощь", commands.Help(g.Lang, false), nil},
+		{"время", "прошло 2 хода", nil},
+		{"применить ключ дверь", "дверь открыта, но скоро захлопнется", nil},
+		{"сохранить ход3", "игра сохранена: ход3", nil},
+		{"осмотреться", "на столе: ничего. можно пройти - улица", []string{"дверь захлопнулась"}},
+		{"осмотреться", "на столе: ничего. можно пройти - улица", []string{"часы пробили"}},
+		{"идти улица", "дверь закрыта", nil},
+		{"загрузить ход3", "игра загружена: ход3", nil},
+		{"время", "прошло 3 хода", nil},
+		// дверь захлопнулась за спиной - в коридоре, там этого уже не слышно
+		{"идти улица", "улица. можно пройти - домой", nil},
+		{"осмотреться", "на столе: автобус. можно пройти - домой", []string{"автобус уехал", "часы пробили"}},
+		{"идти домой", "дверь закрыта", nil},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+		if msgs := g.Messages("вася"); !reflect.DeepEqual(msgs, s.messages) {
+			t.Errorf("%s: messages %q, want %q", s.command, msgs, s.messages)
+		}
+	}
+}
+
+// пустые ходы другого игрока двигают часы: ход, после которого сработало событие, уже не отменить
+func TestGameUndoEvents(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "дом", "go": "дом. ", "exits": [{"to": "двор", "two_way": true}]},
+		{"name": "двор", "go": "двор. ", "items": ["автобус"]}
+	],
+	"events": [
+		{"id": "автобус", "at": 4, "room": "двор", "message": "автобус уехал", "effects": [{"remove": "автобус"}]}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	for _, name := range []string{"вася", "петя"} {
+		if _, err := g.Join(name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+
+	steps := []struct {
+		player  string
+		command string
+		answer  string
+	}{
+		{"вася", "идти двор", "двор. можно пройти - дом"},
+		{"петя", "осмотреться", "на столе: ничего. можно пройти - двор"},
+		{"петя", "осмотреться", "на столе: ничего. можно пройти - двор"},
+		{"петя", "осмотреться", "на столе: ничего. можно пройти - двор"},
+		{"вася", "отменить", "отменить нельзя: после вас ходил петя"},
+		{"вася", "осмотреться", "на столе: ничего. можно пройти - дом"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle(s.player, s.command); answer != s.answer {
+			t.Errorf("%s: %s\n\tresult:   %s\n\texpected: %s", s.player, s.command, answer, s.answer)
+		}
+	}
+	if msgs := g.Messages("вася"); !reflect.DeepEqual(msgs, []string{"петя вошёл в игру", "автобус уехал"}) {
+		t.Errorf("вася messages = %q", msgs)
+	}
+}
+
+// названия из мира с заглавной буквы узнаются в любом регистре
+func TestGameNameCase(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "Кухня", "go": "кухня. ", "exits": [{"to": "Коридор", "two_way": true}]},
+		{"name": "Коридор", "go": "коридор. ", "items": [{"name": "Старый рюкзак", "desc": "пыльный"}]}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	for _, s := range []struct {
+		command string
+		answer  string
+	}{
+		{"идти в коридор", "коридор. можно пройти - Кухня"},
+		{`осмотреть "Старый рюкзак"`, "пыльный"},
+		{"осмотреть старый рюкзак", "пыльный"},
+		{"идти на Кухню", "кухня. можно пройти - Коридор"},
+	} {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+}
+
+// соседи слышат, в какую комнату ушёл игрок, а не как называется выход
+func TestGameLeftByLabel(t *testing.T) {
+	w, err := world.Parse([]byte(`{
+	"rooms": [
+		{"name": "двор", "go": "двор. ", "exits": [{"to": "дом", "label": "домой", "two_way": true}]},
+		{"name": "дом", "go": "дом. "}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse world: %v", err)
+	}
+	g := NewGame(w)
+	for _, name := range []string{"вася", "петя"} {
+		if _, err := g.Join(name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+	if answer := g.Handle("вася", "идти домой"); answer != "дом. можно пройти - двор" {
+		t.Errorf("идти домой: %s", answer)
+	}
+	if msgs, want := g.Messages("петя"), []string{"вася ушёл в дом"}; !reflect.DeepEqual(msgs, want) {
+		t.Errorf("петя messages = %q, want %q", msgs, want)
+	}
+}
+
+func TestGameEnglish(t *testing.T) {
+	g := newTestGame(t, "вася", "петя")
+
+	steps := []struct {
+		player  string
+		command string
+		answer  string
+	}{
+		{"вася", "language de", "нет языка de, есть: en, ru"},
+		{"вася", "lang en", "language: en"},
+		{"вася", "look", "you are in the kitchen, on the table: tea, you need to pack the backpack and go to the university. also here: петя. you can go to - hallway"},
+		{"вася", "go to the room", "nothing interesting. you can go to - kitchen, room, street\nyou are in your room. you can go to - hallway"},
+		{"вася", "look", "on the table: keys, notes, on the chair: backpack. you can go to - hallway"},
+		{"вася", "take keys", "nowhere to put it"},
+		{"вася", "wear backpack", "you put on: backpack"},
+		{"вася", "take keys", "item added to inventory: keys"},
+		{"вася", "inv", "you are wearing: backpack (keys)"},
+		{"вася", "x backpack", "an old backpack. inside: keys"},
+		{"вася", "go street", "no way to street"},
+		{"вася", "use keys", "not enough arguments: use <what> <to what>"},
+		{"вася", "time", "9 turns have passed"},
+		{"петя", "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+		{"петя", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"петя", "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"петя", "осмотреться", "на столе: конспекты. здесь также: вася. можно пройти - коридор"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle(s.player, s.command); answer != s.answer {
+			t.Errorf("%s: %s\n\tresult:   %s\n\texpected: %s", s.player, s.command, answer, s.answer)
+		}
+	}
+
+	// сообщения переводятся, когда игрок их забирает, поэтому и старые - уже на английском
+	if msgs, want := g.Messages("вася"), []string{"петя joined the game", "петя came in"}; !reflect.DeepEqual(msgs, want) {
+		t.Errorf("вася messages = %q, want %q", msgs, want)
+	}
+	g.Handle("вася", "drop keys")
+	if msgs, want := g.Messages("петя"), []string{"вася ушёл в коридор", "вася выложил ключи"}; !reflect.DeepEqual(msgs, want) {
+		t.Errorf("петя messages = %q, want %q", msgs, want)
+	}
+}
+
+func TestGameAdmin(t *testing.T) {
+	g := newTestGame(t)
+	g.Admins = map[string]bool{"вася": true}
+	for _, name := range []string{"вася", "петя"} {
+		if _, err := g.Join(name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+
+	if answer := g.Handle("петя", "телепорт улица"); answer != "неизвестная команда" {
+		t.Fatalf("admin command without rights: %s", answer)
+	}
+	if help := g.Handle("петя", "помощь"); strings.Contains(help, "телепорт") {
+		t.Errorf("admin command in help:\n%s", help)
+	}
+	if help := g.Handle("вася", "помощь"); !strings.Contains(help, "телепорт <куда>") {
+		t.Errorf("no admin command in help:\n%s", help)
+	}
+
+	steps := []struct {
+		command string
+		answer  string
+	}{
+		{"телепорт чердак", "нет комнаты чердак"},
+		{"телепорт комната", "на столе: ключи, конспекты, на стуле: рюкзак. можно пройти - коридор"},
+		{"уничтожить конспекты", "предмет уничтожен: конспекты"},
+		{"уничтожить конспекты", "нет такого - конспекты"},
+		{"создать зонт", "создан предмет: зонт"},
+		{"осмотреться", "на столе: ключи, зонт, на стуле: рюкзак. можно пройти - коридор"},
+		{"отпереть люк", "нет двери люк"},
+		{"отпереть входная", "дверь открыта"},
+		{"комнаты", "комнаты:\nулица\nкухня: петя\nкомната: вася\nкоридор"},
+		{"время", "прошёл 1 ход"},
+		{"телепорт коридор", "на столе: ничего. можно пройти - кухня, комната, улица"},
+		{"идти улица", "на улице весна. можно пройти - домой"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+	msgs := g.Messages("петя")
+	want := []string{"вася исчез"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Errorf("петя messages = %q, want %q", msgs, want)
+	}
+
+	var dump struct {
+		Turn   int `json:"turn"`
+		Player struct {
+			Name string `json:"name"`
+			Room string `json:"room"`
+		} `json:"player"`
+		Room struct {
+			ID string `json:"id"`
+		} `json:"room"`
+	}
+	if err := json.Unmarshal([]byte(g.Handle("вася", "состояние")), &dump); err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	if dump.Player.Name != "вася" || dump.Player.Room != "улица" || dump.Room.ID != "улица" || dump.Turn != 2 {
+		t.Errorf("dump: %+v", dump)
+	}
+
+	// команды мастера отменяются, как обычные ходы
+	for _, s := range []struct {
+		command string
+		answer  string
+	}{
+		{"отменить", "отменено: идти улица"},
+		{"отменить", "отменено: телепорт коридор"},
+		{"отменить", "отменено: отпереть входная"},
+		{"телепорт коридор", "на столе: ничего. можно пройти - кухня, комната, улица"},
+		{"идти улица", "дверь закрыта"},
+	} {
+		if answer := g.Handle("вася", s.command); answer != s.answer {
+			t.Errorf("%s\n\tresult:   %s\n\texpected: %s", s.command, answer, s.answer)
+		}
+	}
+
+	g.Debug = true
+	if answer := g.Handle("петя", "телепорт улица"); answer != "на столе: ничего. можно пройти - домой" {
+		t.Errorf("debug teleport: %s", answer)
+	}
+}
+
+func TestGameEvents(t *testing.T) {
+	g := newTestGame(t)
+	var got []event.Event
+	g.World.Bus.Subscribe(event.Func(func(e event.Event) { got = append(got, e) }))
+
+	if _, err := g.Join("вася"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	for _, cmd := range []string{
+		"идти коридор", "идти комната", "надеть рюкзак", "взять ключи", "взять ключи", "взять конспекты",
+		"идти коридор", "применить ключи дверь", "идти улица", "отменить",
+	} {
+		g.Handle("вася", cmd)
+	}
+	g.Leave("вася")
+
+	want := []event.Event{
+		{Kind: event.Join, Player: "вася", Room: "кухня"},
+		{Kind: event.Move, Turn: 0, Player: "вася", Room: "коридор", From: "кухня"},
+		{Kind: event.Move, Turn: 1, Player: "вася", Room: "комната", From: "коридор"},
+		{Kind: event.Wear, Turn: 2, Player: "вася", Room: "комната", Item: "рюкзак"},
+		{Kind: event.Take, Turn: 3, Player: "вася", Room: "комната", Item: "ключи"},
+		{Kind: event.Take, Turn: 5, Player: "вася", Room: "комната", Item: "конспекты"},
+		{Kind: event.Move, Turn: 6, Player: "вася", Room: "коридор", From: "комната"},
+		{Kind: event.Use, Turn: 7, Player: "вася", Room: "коридор", Item: "ключи", Target: "дверь"},
+		{Kind: event.Unlock, Turn: 7, Player: "вася", Room: "коридор", Door: "входная"},
+		{Kind: event.Move, Turn: 8, Player: "вася", Room: "улица", From: "коридор"},
+		{Kind: event.Quest, Turn: 9, Player: "вася", Room: "улица", Target: "универ"},
+		{Kind: event.Undo, Turn: 8, Player: "вася", Room: "коридор", Target: "идти улица"},
+		{Kind: event.Leave, Turn: 8, Player: "вася", Room: "коридор"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events\n\tresult:   %+v\n\texpected: %+v", got, want)
+	}
+}
+
+func TestGameUndo(t *testing.T) {
+	g := newTestGame(t, "вася", "петя")
+
+	steps := []struct {
+		player  string
+		command string
+		answer  string
+	}{
+		{"вася", "отменить", "отменять нечего"},
+		{"вася", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"вася", "идти комната", "ты в своей комнате. можно пройти - коридор"},
+		{"вася", "надеть рюкзак", "вы надели: рюкзак"},
+		{"вася", "отметка рюкзак", "отметка поставлена: рюкзак"},
+		{"вася", "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"вася", "взять конспекты", "предмет добавлен в инвентарь: конспекты"},
+		{"вася", "отменить", "отменено: взять конспекты"},
+		// осмотреться и неудачные команды ничего не меняют, отменяется ход до них
+		{"вася", "осмотреться", "на столе: конспекты. можно пройти - коридор"},
+		{"вася", "взять чай", "нет такого"},
+		{"вася", "отменить", "отменено: взять ключи"},
+		{"вася", "осмотреться", "на столе: ключи, конспекты. можно пройти - коридор"},
+		{"вася", "взять ключи", "предмет добавлен в инвентарь: ключи"},
+		{"вася", "время", "прошло 5 ходов"},
+		{"вася", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"вася", "применить ключи дверь", "дверь открыта"},
+		{"петя", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"вася", "отменить", "отменить нельзя: после вас ходил петя"},
+		{"петя", "отменить", "отменено: идти коридор"},
+		{"вася", "отменить", "отменено: применить ключи дверь"},
+		{"вася", "идти улица", "дверь закрыта"},
+		{"вася", "отменить", "отменено: идти коридор"},
+		{"вася", "осмотреться", "на столе: конспекты. можно пройти - коридор"},
+		{"вася", "инвентарь", "на вас: рюкзак (ключи)"},
+		{"вася", "вернуться рюкзак", "вернуться нельзя: после отметки ходил петя"},
+		{"петя", "вернуться рюкзак", "нет отметки рюкзак"},
+	}
+	for _, s := range steps {
+		if answer := g.Handle(s.player, s.command); answer != s.answer {
+			t.Errorf("%s: %s\n\tresult:   %s\n\texpected: %s", s.player, s.command, answer, s.answer)
+		}
+	}
+
+	msgs := g.Messages("петя")
+	want := []string{"вася ушёл в коридор", "вася отменил свой ход", "вася отменил свой ход", "вася отменил свой ход", "вася отменил свой ход"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Errorf("петя messages = %q, want %q", msgs, want)
+	}
+
+	// к отметке не вернуться, если после неё мир менял кто-то другой
+	for _, s := range []struct {
+		player  string
+		command string
+		answer  string
+	}{
+		{"петя", "отметка кухня", "отметка поставлена: кухня"},
+		{"вася", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"петя", "вернуться кухня", "вернуться нельзя: после отметки ходил вася"},
+		{"вася", "отменить", "отменено: идти коридор"},
+		{"петя", "вернуться кухня", "вернуться нельзя: после отметки ходил вася"},
+		{"петя", "отметка кухня", "отметка поставлена: кухня"},
+		{"петя", "идти коридор", "ничего интересного. можно пройти - кухня, комната, улица"},
+		{"петя", "вернуться кухня", "вы вернулись к отметке кухня"},
+		{"вася", "отменить", "отменять нечего"},
+		{"петя", "осмотреться", "ты находишься на кухне, на столе: чай, надо собрать рюкзак и идти в универ. можно пройти - коридор"},
+	} {
+		if answer := g.Handle(s.player, s.command); answer != s.answer {
+			t.Errorf("%s: %s\n\tresult:   %s\n\texpected: %s", s.player, s.command, answer, s.answer)
+		}
+	}
+}
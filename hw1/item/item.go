@@ -1,5 +1,109 @@
 package item
 
-type Item struct{
-	Name string
+import "strings"
+
+// Item - предмет; если Capacity > 0, в него можно класть другие предметы
+// общим весом не больше Capacity. Place - ID места в комнате (room.Surface), где лежит предмет
+type Item struct {
+	Name     string  `json:"name"`
+	Desc     string  `json:"desc,omitempty"`
+	Weight   int     `json:"weight,omitempty"`
+	Capacity int     `json:"capacity,omitempty"`
+	Wearable bool    `json:"wearable,omitempty"`
+	Place    string  `json:"place,omitempty"`
+	Contents []*Item `json:"contents,omitempty"`
+}
+
+func New(name string) *Item {
+	return &Item{Name: name, Weight: 1}
+}
+
+func (it *Item) IsContainer() bool {
+	return it.Capacity > 0
+}
+
+// TotalWeight - вес предмета вместе с содержимым
+func (it *Item) TotalWeight() int {
+	w := it.Weight
+	for _, in := range it.Contents {
+		w += in.TotalWeight()
+	}
+	return w
+}
+
+func (it *Item) Fits(other *Item) bool {
+	if !it.IsContainer() || other == it || other.Holds(it) {
+		return false
+	}
+	return it.TotalWeight()-it.Weight+other.TotalWeight() <= it.Capacity
+}
+
+// Holds - лежит ли other внутри предмета, на любой глубине
+func (it *Item) Holds(other *Item) bool {
+	for _, in := range it.Contents {
+		if in == other || in.Holds(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe - имя предмета и, для контейнеров, что в нём лежит: "рюкзак (ключи, конспекты)";
+// word переводит имена на язык игрока
+func (it *Item) Describe(word func(string) string) string {
+	if len(it.Contents) == 0 {
+		return word(it.Name)
+	}
+	inside := make([]string, 0, len(it.Contents))
+	for _, in := range it.Contents {
+		inside = append(inside, in.Describe(word))
+	}
+	return word(it.Name) + " (" + strings.Join(inside, ", ") + ")"
+}
+
+func (it *Item) Put(other *Item) {
+	other.Place = ""
+	it.Contents = append(it.Contents, other)
+}
+
+// Clone - глубокая копия предмета
+func (it *Item) Clone() *Item {
+	cp := *it
+	cp.Contents = CloneAll(it.Contents)
+	return &cp
+}
+
+func CloneAll(items []*Item) []*Item {
+	res := make([]*Item, 0, len(items))
+	for _, it := range items {
+		res = append(res, it.Clone())
+	}
+	return res
+}
+
+// Find ищет предмет среди items и у них внутри
+func Find(items []*Item, name string) *Item {
+	for _, it := range items {
+		if it.Name == name {
+			return it
+		}
+		if in := Find(it.Contents, name); in != nil {
+			return in
+		}
+	}
+	return nil
+}
+
+// Remove вынимает предмет из items или у них изнутри
+func Remove(items []*Item, name string) ([]*Item, *Item) {
+	for idx, it := range items {
+		if it.Name == name {
+			return append(items[:idx], items[idx+1:]...), it
+		}
+		var found *Item
+		if it.Contents, found = Remove(it.Contents, name); found != nil {
+			return items, found
+		}
+	}
+	return items, nil
 }
@@ -1,12 +1,18 @@
 package main
 
 import (
+	_ "embed"
+	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/Keniden/vk-homework/game/item"
-	"github.com/Keniden/vk-homework/game/room"
-	"github.com/Keniden/vk-homework/game/user"
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/world"
 )
 
 /*
@@ -14,36 +20,33 @@ import (
 	наверняка у вас будут какие-то структуры с методами, глобальные переменные ( тут можно ), функции
 */
 
-var gamer *user.User
+var game *Game
+
+// игрок, от имени которого работает handleCommand
+const defaultPlayer = "игрок"
+
+//go:embed world.json
+var defaultWorld []byte
+
+// initGame берёт мир отсюда; main подменяет загрузку файлом из -world
+var loadWorld = func() (*world.World, error) {
+	return world.Parse(defaultWorld)
+}
 
 func initGame() {
 	/*
 		эта функция инициализирует игровой мир - все комнаты
 		если что-то было - оно корректно перезатирается
 	*/
-	street := room.NewRoom("улица", "", "на улице весна. ", "", []*item.Item{})
-	kitchen := room.NewRoom("кухня", "ты находишься на кухне, ", "кухня, ничего интересного. ", "надо собрать рюкзак и идти в универ.", []*item.Item{})
-	myRoom := room.NewRoom("комната", "", "ты в своей комнате. ", "", []*item.Item{})
-	hall := room.NewRoom("коридор", "", "ничего интересного. ", "", []*item.Item{})
-
-	myRoom.AddBackpack()
-
-	hall.ItHall()
-
-	street.AddRout(hall)
-	kitchen.AddRout(hall)
-	myRoom.AddRout(hall)
-	hall.AddRout(kitchen)
-	hall.AddRout(myRoom)
-	hall.AddRout(street)
-
-	kitchen.AddItem("чай")
-
-	myRoom.AddItem("ключи")
-	myRoom.AddItem("конспекты")
-
-	gamer = user.NewUser(kitchen)
+	w, err := loadWorld()
+	if err != nil {
+		panic(err)
+	}
 
+	game = NewGame(w)
+	if _, err := game.Join(defaultPlayer); err != nil {
+		panic(err)
+	}
 }
 
 func handleCommand(command string) string {
@@ -51,26 +54,7 @@ func handleCommand(command string) string {
 		данная функция принимает команду от "пользователя"
 		и наверняка вызывает какой-то другой метод или функцию у "мира" - списка комнат
 	*/
-	cmd := strings.Split(command, " ")
-
-	switch cmd[0] {
-	case "осмотреться":
-		return gamer.Look()
-	case "идти":
-		return gamer.GoTo(cmd[1])
-
-	case "надеть":
-		return gamer.PutOnBackpack()
-	case "взять":
-		return gamer.Take(cmd[1])
-
-	case "применить":
-		return gamer.Use(cmd[1], cmd[2])
-
-	default:
-		return "неизвестная команда"
-	}
-
+	return game.Handle(defaultPlayer, command)
 }
 
 func main() {
@@ -79,6 +63,163 @@ func main() {
 		но тогда у вас не будет работать через go run main.go
 		очень круто будет сделать построчный ввод команд тут, хотя это и не требуется по заданию
 	*/
-	initGame()
-	fmt.Println(handleCommand("идти улица"))
+	worldFile := flag.String("world", "", "файл с описанием мира (json)")
+	listen := flag.String("listen", "", "адрес, на котором поднять tcp сервер, например :4000")
+	httpAddr := flag.String("http", "", "адрес, на котором поднять http/json api, например :8080")
+	saves := flag.String("saves", "saves", "папка для сохранений")
+	lang := flag.String("lang", "ru", "язык игры: ru или en, игрок может сменить его командой \"язык\"")
+	debug := flag.Bool("debug", false, "режим отладки: всем игрокам доступны команды мастера")
+	admins := flag.String("admins", "", "имена игроков через запятую, которым доступны команды мастера")
+	idle := flag.Duration("idle", 10*time.Minute, "через сколько отключать молчащего игрока")
+	check := flag.Bool("check", false, "проверить мир и выйти, код 1 - если есть ошибки")
+	mapFormat := flag.String("map", "", "напечатать карту мира в формате dot или mermaid и выйти")
+	auditFile := flag.String("audit", "", "файл, куда дописывать события мира, по json в строке")
+	script := flag.String("script", "", "файл с командами, по одной в строке; - читать команды из stdin без приглашения")
+	flag.Parse()
+
+	if !slices.Contains(msg.Langs(), *lang) {
+		fmt.Fprintf(os.Stderr, "неизвестный язык %q, есть %s\n", *lang, strings.Join(msg.Langs(), ", "))
+		os.Exit(1)
+	}
+	if *worldFile != "" {
+		loadWorld = func() (*world.World, error) {
+			return world.Load(*worldFile)
+		}
+		if _, err := loadWorld(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if *check || *mapFormat != "" {
+		w, err := loadWorld()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(inspectWorld(w, *check, *mapFormat))
+	}
+
+	if *auditFile != "" {
+		f, err := os.OpenFile(*auditFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		load := loadWorld
+		loadWorld = func() (*world.World, error) {
+			w, err := load()
+			if err == nil {
+				w.Bus.Subscribe(event.NewJSONL(f))
+			}
+			return w, err
+		}
+	}
+
+	// игра настраивается флагами одинаково для http, tcp и консоли
+	newConfiguredGame := func(w *world.World) *Game {
+		g := NewGame(w)
+		g.SaveDir = *saves
+		g.Lang = w.Locale(*lang)
+		g.Debug = *debug
+		g.Admins = names(*admins)
+		return g
+	}
+
+	if *httpAddr != "" {
+		w, err := loadWorld()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		api := NewAPI(newConfiguredGame(w))
+		api.IdleTimeout = *idle
+		if err := http.ListenAndServe(*httpAddr, api); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *listen != "" {
+		w, err := loadWorld()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		s := &Server{Game: newConfiguredGame(w), IdleTimeout: *idle}
+		if err := s.ListenAndServe(*listen); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// как initGame, но игрок входит уже в настроенную игру и получает её язык и права мастера
+	w, err := loadWorld()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	game = newConfiguredGame(w)
+	if _, err := game.Join(defaultPlayer); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	repl := &REPL{Game: game, Player: defaultPlayer}
+	in := os.Stdin
+	if *script != "" {
+		repl.Script = true
+		if *script != "-" {
+			f, err := os.Open(*script)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			in = f
+		}
+	} else {
+		fmt.Println(game.Look(defaultPlayer))
+	}
+	if err := repl.Run(in, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// names - множество имён из списка через запятую
+func names(list string) map[string]bool {
+	res := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			res[name] = true
+		}
+	}
+	return res
+}
+
+// inspectWorld печатает проблемы мира или его карту и возвращает код выхода
+func inspectWorld(w *world.World, check bool, mapFormat string) int {
+	code := 0
+	if check {
+		for _, p := range w.Check() {
+			fmt.Fprintln(os.Stderr, p)
+			if p.Error {
+				code = 1
+			}
+		}
+	}
+	switch mapFormat {
+	case "":
+	case "dot":
+		fmt.Print(w.DOT())
+	case "mermaid":
+		fmt.Print(w.Mermaid())
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестный формат карты %q, есть dot и mermaid\n", mapFormat)
+		return 2
+	}
+	return code
 }
@@ -0,0 +1,138 @@
+package msg
+
+var en = map[string]string{
+	"unknown":    "unknown command",
+	"quote":      "unclosed quote",
+	"args.few":   "not enough arguments: {0}",
+	"args.many":  "too many arguments: {0}",
+	"over":       "the game is over",
+	"no_player":  "no player {0}",
+	"time":       "{0} {0#turn has|turns have} passed",
+	"lang.set":   "language: {0}",
+	"lang.none":  "no language {0}, available: {1}",
+	"help":       "commands:",
+	"bye":        "see you",
+	"idle":       "idle timeout, see you",
+	"login":      "what is your name?",
+	"history.no": "history is empty",
+	"history.n":  "no command {0} in history",
+	"frontend":   "this command only works in the console",
+
+	"join.empty": "empty player name",
+	"join.taken": "player {0} is already in the game",
+	"join.say":   "{0} joined the game",
+	"quit.say":   "{0} left the game",
+
+	"save.bad":   "bad save name - {0}",
+	"save.fail":  "could not save: {0}",
+	"save.done":  "game saved: {0}",
+	"load.none":  "no save {0}",
+	"load.fail":  "could not load: {0}",
+	"load.done":  "game loaded: {0}",
+	"load.say":   "{0} loaded save {1}",
+	"undo.none":  "nothing to undo",
+	"undo.other": "can't undo: {0} moved after you",
+	"undo.done":  "undone: {0}",
+	"undo.say":   "{0} took back a move",
+	"mark.done":  "checkpoint set: {0}",
+	"back.none":  "no checkpoint {0}",
+	"back.other": "can't go back: {0} moved after the checkpoint",
+	"back.done":  "back at checkpoint {0}",
+	"back.say":   "{0} returned the world to checkpoint {1}",
+	"quest.say":  "{0} completed a quest",
+	"quest.and":  " and ",
+	"quest.todo": "{0} {1}.",
+
+	"look.nothing": "nothing",
+	"look.place":   "{0}: {1}",
+	"look.others":  "also here: {0}.",
+	"exits":        "you can go to - {0}",
+	"exits.none":   "nowhere",
+
+	"go.no":      "no way to {0}",
+	"go.closed":  "{0} is closed",
+	"go.here":    "you are already here - {0}",
+	"go.left":    "{0} went to {1}",
+	"go.came":    "{0} came in",
+	"wear.no":    "{0} cannot be worn",
+	"wear.done":  "you put on: {0}",
+	"wear.say":   "{0} put on {1}",
+	"no_such":    "no such thing",
+	"no_such.x":  "no such thing - {0}",
+	"take.no":    "nowhere to put it",
+	"take.done":  "item added to inventory: {0}",
+	"take.say":   "{0} took {1}",
+	"put.no":     "nothing fits into {0}",
+	"put.fit":    "{0} does not fit into {1}",
+	"put.done":   "you put {0} into {1}",
+	"put.say":    "{0} put {1} into {2}",
+	"inv.empty":  "inventory is empty",
+	"inv.worn":   "you are wearing: {0}",
+	"inv.no":     "no such item in inventory - {0}",
+	"drop.done":  "you dropped: {0}",
+	"drop.say":   "{0} dropped {1}",
+	"examine":    "{0}, nothing special",
+	"examine.in": "{0}. inside: {1}",
+	"examine.no": "{0}. it is empty inside",
+	"use.done":   "you used {0} on {1}",
+	"use.say":    "{0} used {1}",
+	"use.no":     "nothing to use it on",
+	"door.open":  "{0} is open",
+	"door.was":   "{0} is already open",
+	"door.say":   "{0} opened {1}",
+
+	"admin.no_room": "no room {0}",
+	"admin.no_door": "no door {0}",
+	"admin.vanish":  "{0} vanished",
+	"admin.appear":  "{0} appeared",
+	"admin.spawn":   "item created: {0}",
+	"admin.destroy": "item destroyed: {0}",
+	"admin.rooms":   "rooms:",
+
+	"npc.no":      "{0} is not here",
+	"npc.huh":     "{0} does not understand",
+	"npc.silent":  "{0} says nothing",
+	"npc.line":    "{0}: {1}",
+	"npc.choices": "{0} you can answer - {1}",
+	"npc.say":     "{0} is talking to {1}",
+
+	"help.осмотреться": "describe the room",
+	"help.идти":        "go to the next room",
+	"help.дойти":       "walk to a room by the shortest path",
+	"help.надеть":      "put on something from the room",
+	"help.взять":       "put an item from the room into your bag",
+	"help.положить":    "put an item into a bag or a box",
+	"help.инвентарь":   "what you carry",
+	"help.выложить":    "drop an item from the inventory",
+	"help.осмотреть":   "describe an item",
+	"help.применить":   "use an item from the inventory",
+	"help.поговорить":  "start talking to a character",
+	"help.сказать":     "answer a character with a phrase or an answer number",
+	"help.сохранить":   "save the game",
+	"help.загрузить":   "load a saved game",
+	"help.отменить":    "take back the last move",
+	"help.отметка":     "remember how things are now to come back later",
+	"help.вернуться":   "return to a checkpoint",
+	"help.время":       "how many turns have passed",
+	"help.язык":        "choose the game language",
+	"help.помощь":      "list commands",
+	"help.выход":       "leave the game",
+	"help.история":     "show entered commands, repeat them with !! or !N",
+	"help.телепорт":    "jump to any room",
+	"help.создать":     "create an item in the room",
+	"help.уничтожить":  "remove an item from the room or the inventory",
+	"help.отпереть":    "unlock a door anywhere",
+	"help.состояние":   "player and room state as json",
+	"help.комнаты":     "all rooms of the world and who is there",
+
+	"arg.куда":   "where",
+	"arg.что":    "what",
+	"arg.к чему": "to what",
+	"arg.с кем":  "whom",
+	"arg.кому":   "to whom",
+	"arg.фраза":  "phrase",
+	"arg.слот":   "slot",
+	"arg.язык":   "language",
+	"arg.дверь":  "door",
+	"arg.имя":    "name",
+}
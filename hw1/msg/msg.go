@@ -0,0 +1,191 @@
+package msg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+	каталог сообщений игры
+	шаблон сообщения ссылается на аргументы по номеру:
+		{0}          - аргумент как есть (строки переводятся словарём мира)
+		{1:вин}      - форма слова в падеже из Forms, если она известна
+		{0#ход|хода|ходов} - форма по числу, правила - свои у каждого языка
+*/
+
+// Text - сообщение каталога с аргументами; переводится, когда известно, кому его показать
+type Text struct {
+	ID   string
+	Args []any
+}
+
+func T(id string, args ...any) Text {
+	return Text{ID: id, Args: args}
+}
+
+// Raw - текст из описания мира, он только переводится словарём
+func Raw(s string) Text {
+	return Text{Args: []any{s}}
+}
+
+type catalog struct {
+	messages map[string]string
+	plural   func(n int) int
+}
+
+var catalogs = map[string]catalog{
+	"ru": {messages: ru, plural: pluralRu},
+	"en": {messages: en, plural: pluralEn},
+}
+
+// Langs - какие языки есть в каталоге
+func Langs() []string {
+	res := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		res = append(res, lang)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Locale - язык игрока: сообщения каталога, перевод текстов мира Words
+// и формы слов мира по падежам Forms
+type Locale struct {
+	Lang  string
+	Words map[string]string
+	Forms map[string]map[string]string
+
+	catalog
+}
+
+func New(lang string, words map[string]string, forms map[string]map[string]string) (*Locale, error) {
+	c, ok := catalogs[lang]
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q", lang)
+	}
+	return &Locale{Lang: lang, Words: words, Forms: forms, catalog: c}, nil
+}
+
+// Word переводит текст мира; пробелы по краям сохраняются, неизвестный текст остаётся как есть
+func (l *Locale) Word(s string) string {
+	if tr, ok := l.Words[s]; ok {
+		return tr
+	}
+	trimmed := strings.TrimSpace(s)
+	tr, ok := l.Words[trimmed]
+	if !ok || trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + tr + s[start+len(trimmed):]
+}
+
+// Form - слово мира в падеже
+func (l *Locale) Form(s, form string) string {
+	if f, ok := l.Forms[s][form]; ok {
+		return f
+	}
+	return l.Word(s)
+}
+
+func (l *Locale) Sprint(id string, args ...any) string {
+	return l.Render(T(id, args...))
+}
+
+func (l *Locale) Render(t Text) string {
+	if t.ID == "" {
+		if len(t.Args) == 0 {
+			return ""
+		}
+		return l.arg(t.Args[0], "")
+	}
+	tmpl, ok := l.messages[t.ID]
+	if !ok {
+		tmpl, ok = ru[t.ID]
+	}
+	if !ok {
+		return t.ID
+	}
+
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(tmpl, '{')
+		if open < 0 {
+			b.WriteString(tmpl)
+			break
+		}
+		end := strings.IndexByte(tmpl[open:], '}')
+		if end < 0 {
+			b.WriteString(tmpl)
+			break
+		}
+		b.WriteString(tmpl[:open])
+		b.WriteString(l.placeholder(tmpl[open+1:open+end], t.Args))
+		tmpl = tmpl[open+end+1:]
+	}
+	return b.String()
+}
+
+// placeholder подставляет "0", "1:вин" или "0#ход|хода|ходов"
+func (l *Locale) placeholder(ph string, args []any) string {
+	num, rest := ph, ""
+	if i := strings.IndexAny(ph, ":#"); i >= 0 {
+		num, rest = ph[:i], ph[i:]
+	}
+	idx, err := strconv.Atoi(num)
+	if err != nil || idx < 0 || idx >= len(args) {
+		return "{" + ph + "}"
+	}
+	arg := args[idx]
+	if strings.HasPrefix(rest, "#") {
+		n, ok := arg.(int)
+		if !ok {
+			return fmt.Sprint(arg)
+		}
+		forms := strings.Split(rest[1:], "|")
+		i := l.plural(n)
+		if i >= len(forms) {
+			i = len(forms) - 1
+		}
+		return forms[i]
+	}
+	return l.arg(arg, strings.TrimPrefix(rest, ":"))
+}
+
+func (l *Locale) arg(arg any, form string) string {
+	switch a := arg.(type) {
+	case string:
+		if form != "" {
+			return l.Form(a, form)
+		}
+		return l.Word(a)
+	case Text:
+		return l.Render(a)
+	case []string:
+		words := make([]string, 0, len(a))
+		for _, w := range a {
+			words = append(words, l.Word(w))
+		}
+		return strings.Join(words, ", ")
+	}
+	return fmt.Sprint(arg)
+}
+
+func pluralRu(n int) int {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+		return 1
+	}
+	return 2
+}
+
+func pluralEn(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
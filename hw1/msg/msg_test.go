@@ -0,0 +1,58 @@
+package msg
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	ruL, err := New("ru", nil, map[string]map[string]string{"комната": {"вин": "комнату"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enL, err := New("en", map[string]string{"комната": "room", "ты в своей комнате.": "you are in your room."}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		l    *Locale
+		text Text
+		want string
+	}{
+		{ruL, T("go.no", "комната"), "нет пути в комната"},
+		{enL, T("go.no", "комната"), "no way to room"},
+		{ruL, T("go.left", "вася", "комната"), "вася ушёл в комнату"},
+		{ruL, T("go.left", "вася", "коридор"), "вася ушёл в коридор"},
+		{enL, T("go.left", "вася", "комната"), "вася went to room"},
+		{enL, Raw("ты в своей комнате. "), "you are in your room. "},
+		{enL, T("exits", []string{"комната", "кухня"}), "you can go to - room, кухня"},
+		{ruL, T("time", 1), "прошёл 1 ход"},
+		{ruL, T("time", 3), "прошло 3 хода"},
+		{ruL, T("time", 11), "прошло 11 ходов"},
+		{ruL, T("time", 21), "прошёл 21 ход"},
+		{enL, T("time", 1), "1 turn has passed"},
+		{enL, T("time", 2), "2 turns have passed"},
+		{enL, T("no.such.id"), "no.such.id"},
+	}
+	for _, c := range cases {
+		if got := c.l.Render(c.text); got != c.want {
+			t.Errorf("%s %v: %q, want %q", c.l.Lang, c.text, got, c.want)
+		}
+	}
+
+	if got := ruL.Form("комната", "вин"); got != "комнату" {
+		t.Errorf("form: %q", got)
+	}
+	if _, err := New("de", nil, nil); err == nil {
+		t.Errorf("expected error for unknown language")
+	}
+}
+
+// в каждом языке должны быть все сообщения русского каталога
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Langs() {
+		for id := range ru {
+			if _, ok := catalogs[lang].messages[id]; !ok {
+				t.Errorf("%s: no message %q", lang, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package msg
+
+var ru = map[string]string{
+	"unknown":    "неизвестная команда",
+	"quote":      "не закрыта кавычка",
+	"args.few":   "не хватает аргументов: {0}",
+	"args.many":  "лишние аргументы: {0}",
+	"over":       "игра окончена",
+	"no_player":  "нет игрока {0}",
+	"time":       "{0#прошёл|прошло|прошло} {0} {0#ход|хода|ходов}",
+	"lang.set":   "язык: {0}",
+	"lang.none":  "нет языка {0}, есть: {1}",
+	"help":       "команды:",
+	"bye":        "до встречи",
+	"idle":       "время ожидания истекло, до встречи",
+	"login":      "как тебя зовут?",
+	"history.no": "история пуста",
+	"history.n":  "нет команды {0} в истории",
+	"frontend":   "эта команда работает только в консоли",
+
+	"join.empty": "пустое имя игрока",
+	"join.taken": "игрок {0} уже в игре",
+	"join.say":   "{0} вошёл в игру",
+	"quit.say":   "{0} ушёл из игры",
+
+	"save.bad":   "неправильное имя сохранения - {0}",
+	"save.fail":  "не удалось сохранить: {0}",
+	"save.done":  "игра сохранена: {0}",
+	"load.none":  "нет сохранения {0}",
+	"load.fail":  "не удалось загрузить: {0}",
+	"load.done":  "игра загружена: {0}",
+	"load.say":   "{0} загрузил сохранение {1}",
+	"undo.none":  "отменять нечего",
+	"undo.other": "отменить нельзя: после вас ходил {0}",
+	"undo.done":  "отменено: {0}",
+	"undo.say":   "{0} отменил свой ход",
+	"mark.done":  "отметка поставлена: {0}",
+	"back.none":  "нет отметки {0}",
+	"back.other": "вернуться нельзя: после отметки ходил {0}",
+	"back.done":  "вы вернулись к отметке {0}",
+	"back.say":   "{0} вернул мир к своей отметке {1}",
+	"quest.say":  "{0} выполнил задание",
+	"quest.and":  " и ",
+	"quest.todo": "{0} {1}.",
+
+	"look.nothing": "ничего",
+	"look.place":   "{0}: {1}",
+	"look.others":  "здесь также: {0}.",
+	"exits":        "можно пройти - {0}",
+	"exits.none":   "некуда",
+
+	"go.no":      "нет пути в {0}",
+	"go.closed":  "{0} закрыта",
+	"go.here":    "вы уже здесь - {0}",
+	"go.left":    "{0} ушёл в {1:вин}",
+	"go.came":    "{0} пришёл",
+	"wear.no":    "{0} нельзя надеть",
+	"wear.done":  "вы надели: {0}",
+	"wear.say":   "{0} надел {1:вин}",
+	"no_such":    "нет такого",
+	"no_such.x":  "нет такого - {0}",
+	"take.no":    "некуда класть",
+	"take.done":  "предмет добавлен в инвентарь: {0}",
+	"take.say":   "{0} взял {1:вин}",
+	"put.no":     "в {0} ничего не положить",
+	"put.fit":    "{0} не помещается в {1}",
+	"put.done":   "вы положили {0} в {1}",
+	"put.say":    "{0} положил {1:вин} в {2:вин}",
+	"inv.empty":  "инвентарь пуст",
+	"inv.worn":   "на вас: {0}",
+	"inv.no":     "нет предмета в инвентаре - {0}",
+	"drop.done":  "вы выложили: {0}",
+	"drop.say":   "{0} выложил {1:вин}",
+	"examine":    "{0}, ничего особенного",
+	"examine.in": "{0}. внутри: {1}",
+	"examine.no": "{0}. внутри пусто",
+	"use.done":   "вы применили {0} к {1}",
+	"use.say":    "{0} применил {1:вин}",
+	"use.no":     "не к чему применить",
+	"door.open":  "{0} открыта",
+	"door.was":   "{0} уже открыта",
+	"door.say":   "{0} открыл {1:вин}",
+
+	"admin.no_room": "нет комнаты {0}",
+	"admin.no_door": "нет двери {0}",
+	"admin.vanish":  "{0} исчез",
+	"admin.appear":  "{0} появился",
+	"admin.spawn":   "создан предмет: {0}",
+	"admin.destroy": "предмет уничтожен: {0}",
+	"admin.rooms":   "комнаты:",
+
+	"npc.no":      "здесь нет {0}",
+	"npc.huh":     "{0} не понимает",
+	"npc.silent":  "{0} молчит",
+	"npc.line":    "{0}: {1}",
+	"npc.choices": "{0} можно ответить - {1}",
+	"npc.say":     "{0} говорит с {1:твор}",
+
+	"help.осмотреться": "описание комнаты",
+	"help.идти":        "перейти в соседнюю комнату",
+	"help.дойти":       "дойти до комнаты кратчайшим путём",
+	"help.надеть":      "надеть вещь из комнаты",
+	"help.взять":       "положить предмет из комнаты в сумку",
+	"help.положить":    "положить предмет в сумку или ящик",
+	"help.инвентарь":   "что у вас с собой",
+	"help.выложить":    "выложить предмет из инвентаря в комнату",
+	"help.осмотреть":   "описание предмета",
+	"help.применить":   "применить предмет из инвентаря",
+	"help.поговорить":  "начать разговор с персонажем",
+	"help.сказать":     "ответить персонажу фразой или номером ответа",
+	"help.сохранить":   "сохранить игру",
+	"help.загрузить":   "загрузить сохранённую игру",
+	"help.отменить":    "отменить последний ход",
+	"help.отметка":     "запомнить, как всё сейчас, чтобы потом вернуться",
+	"help.вернуться":   "вернуться к отметке",
+	"help.время":       "сколько прошло ходов",
+	"help.язык":        "выбрать язык игры",
+	"help.помощь":      "список команд",
+	"help.выход":       "выйти из игры",
+	"help.история":     "показать введённые команды, повторить их - !! или !N",
+	"help.телепорт":    "перенестись в любую комнату",
+	"help.создать":     "создать предмет в комнате",
+	"help.уничтожить":  "убрать предмет из комнаты или инвентаря",
+	"help.отпереть":    "отпереть дверь где угодно",
+	"help.состояние":   "состояние игрока и комнаты в json",
+	"help.комнаты":     "все комнаты мира и кто в них",
+
+	"arg.куда":   "куда",
+	"arg.что":    "что",
+	"arg.к чему": "к чему",
+	"arg.с кем":  "с кем",
+	"arg.кому":   "кому",
+	"arg.фраза":  "фраза",
+	"arg.слот":   "слот",
+	"arg.язык":   "язык",
+	"arg.дверь":  "дверь",
+	"arg.имя":    "имя",
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/*
+	разбор команды игрока
+	"Взять  «старый рюкзак»", "идти в комнату", "применить ключи к двери"
+	первое слово - глагол, дальше аргументы;
+	предлоги выкидываются, а названия из словаря мира узнаются в любом падеже
+	и приводятся к тому виду, в каком записаны в мире.
+	незнакомые слова остаются как есть
+*/
+
+var ErrQuote = errors.New("не закрыта кавычка")
+
+// Preps - предлоги, которые не бывают аргументами; английские - для игроков с языком en
+var Preps = []string{
+	"в", "во", "на", "к", "ко", "с", "со", "из", "от", "до", "у", "за", "по", "для",
+	"to", "into", "in", "on", "with", "at", "from",
+}
+
+// английские артикли выкидываются, только если за ними идёт название из словаря:
+// "use keys on the door", но "сохранить a" - это сохранение с именем a
+var articles = []string{"the", "a", "an"}
+
+// окончания, от длинных к коротким
+var endings = []string{
+	"ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими",
+	"ый", "ий", "ой", "ей", "ую", "юю", "ая", "яя", "ое", "ее", "ые", "ие", "ых", "их",
+	"ом", "ем", "ам", "ям", "ах", "ях", "ов", "ев", "ью",
+	"у", "ю", "а", "я", "ы", "и", "е", "о", "ь", "й",
+}
+
+// Command - разобранная команда; Preps - выкинутые предлоги по порядку
+type Command struct {
+	Verb  string
+	Args  []string
+	Preps []string
+
+	raw  []string // слова после глагола как есть
+	ends []int    // после какого из raw кончается каждый аргумент
+}
+
+// Tail - всё, что игрок написал после первых n аргументов, без разбора:
+// фразу "иди на улицу" нельзя превращать в "иди улица"
+func (c Command) Tail(n int) string {
+	from := 0
+	if n > 0 && n <= len(c.ends) {
+		from = c.ends[n-1]
+	}
+	return strings.Join(c.raw[from:], " ")
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// name - как название пишут в команде (words, строчными) и во что оно превращается (text, как в мире)
+type name struct {
+	text  string
+	words []string
+	stems []string
+}
+
+func newName(text, alias string) name {
+	n := name{text: text, words: strings.Fields(strings.ToLower(alias))}
+	for _, w := range n.words {
+		n.stems = append(n.stems, Stem(w))
+	}
+	return n
+}
+
+type Parser struct {
+	names []name
+}
+
+// New строит разборщик по словарю: названиям комнат, выходов, дверей и предметов
+func New(vocab []string) *Parser {
+	p := &Parser{}
+	seen := map[string]bool{}
+	for _, v := range vocab {
+		key := strings.ToLower(v)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		p.names = append(p.names, newName(v, v))
+	}
+	p.sort()
+	return p
+}
+
+// Alias учит разборщик ещё одному написанию названия, например переводу: "keys" - это "ключи"
+func (p *Parser) Alias(alias, text string) {
+	if alias == "" || strings.EqualFold(alias, text) {
+		return
+	}
+	p.names = append(p.names, newName(text, alias))
+	p.sort()
+}
+
+// длинные названия пробуются первыми: "старый рюкзак" раньше "рюкзак"
+func (p *Parser) sort() {
+	sort.SliceStable(p.names, func(i, j int) bool {
+		return len(p.names[i].words) > len(p.names[j].words)
+	})
+}
+
+// Parse разбирает строку; пустая строка даёт пустой глагол
+func (p *Parser) Parse(line string) (Command, error) {
+	toks, err := tokenize(line)
+	if err != nil || len(toks) == 0 {
+		return Command{}, err
+	}
+	cmd := Command{Verb: toks[0].text, Args: []string{}}
+	rest := toks[1:]
+	for _, t := range rest {
+		cmd.raw = append(cmd.raw, t.text)
+	}
+	arg := func(text string) {
+		cmd.Args = append(cmd.Args, text)
+		cmd.ends = append(cmd.ends, len(cmd.raw)-len(rest))
+	}
+	for len(rest) > 0 {
+		if rest[0].quoted {
+			text := p.resolve(strings.Fields(rest[0].text), rest[0].text)
+			rest = rest[1:]
+			arg(text)
+			continue
+		}
+		if text, n := p.match(rest); n > 0 {
+			rest = rest[n:]
+			arg(text)
+			continue
+		}
+		word := rest[0].text
+		rest = rest[1:]
+		switch {
+		case slices.Contains(articles, word) && p.known(rest):
+		case slices.Contains(Preps, word):
+			cmd.Preps = append(cmd.Preps, word)
+		default:
+			arg(word)
+		}
+	}
+	return cmd, nil
+}
+
+// known - начинаются ли toks с названия из словаря
+func (p *Parser) known(toks []token) bool {
+	if len(toks) > 0 && toks[0].quoted {
+		return p.resolve(strings.Fields(toks[0].text), "") != ""
+	}
+	_, n := p.match(toks)
+	return n > 0
+}
+
+// resolve узнаёт в словах одно название целиком, иначе отдаёт raw
+func (p *Parser) resolve(words []string, raw string) string {
+	toks := make([]token, 0, len(words))
+	for _, w := range words {
+		toks = append(toks, token{text: w})
+	}
+	if text, n := p.match(toks); n == len(toks) {
+		return text
+	}
+	return raw
+}
+
+// match ищет название, с которого начинаются toks; точное совпадение важнее похожего,
+// а если похожих несколько - непонятно, о чём речь, и ничего не находится
+func (p *Parser) match(toks []token) (string, int) {
+	size := 0
+	var similar []string
+	for _, n := range p.names {
+		if size > 0 && len(n.words) < size {
+			break
+		}
+		if len(n.words) > len(toks) {
+			continue
+		}
+		exact, ok := n.matches(toks)
+		if !ok {
+			continue
+		}
+		if exact {
+			return n.text, len(n.words)
+		}
+		size = len(n.words)
+		if !slices.Contains(similar, n.text) {
+			similar = append(similar, n.text)
+		}
+	}
+	if len(similar) != 1 {
+		return "", 0
+	}
+	return similar[0], size
+}
+
+func (n name) matches(toks []token) (exact, ok bool) {
+	exact = true
+	for i, w := range n.words {
+		t := toks[i]
+		if t.quoted {
+			return false, false
+		}
+		if t.text == w {
+			continue
+		}
+		exact = false
+		if Stem(t.text) != n.stems[i] {
+			return false, false
+		}
+	}
+	return exact, true
+}
+
+// Stem отрезает падежное окончание: "комнату" и "комната" дают "комнат"
+func Stem(word string) string {
+	word = strings.ReplaceAll(word, "ё", "е")
+	for _, e := range endings {
+		if stem, ok := strings.CutSuffix(word, e); ok && len([]rune(stem)) >= 2 {
+			return stem
+		}
+	}
+	return word
+}
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '«' || r == '»' || r == '“' || r == '”'
+}
+
+// tokenize делит строку по пробелам, в кавычках пробелы остаются частью слова
+func tokenize(line string) ([]token, error) {
+	var (
+		toks   []token
+		cur    strings.Builder
+		quoted bool
+	)
+	flush := func(q bool) {
+		text := strings.TrimSpace(cur.String())
+		if !q {
+			text = strings.TrimRight(text, ".,!?;:")
+		}
+		if text != "" {
+			toks = append(toks, token{text: text, quoted: q})
+		}
+		cur.Reset()
+	}
+	for _, r := range strings.ToLower(line) {
+		switch {
+		case isQuote(r):
+			flush(quoted)
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush(false)
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, ErrQuote
+	}
+	flush(false)
+	return toks, nil
+}
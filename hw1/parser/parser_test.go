@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p := New([]string{"комната", "кухня", "ключи", "дверь", "рюкзак", "старый рюкзак", "чай", "чайник"})
+
+	cases := []struct {
+		line string
+		verb string
+		args []string
+	}{
+		{"идти комната", "идти", []string{"комната"}},
+		{"  идти   в   комнату ", "идти", []string{"комната"}},
+		{"Пойти на кухню.", "пойти", []string{"кухня"}},
+		{"применить ключи к двери", "применить", []string{"ключи", "дверь"}},
+		{"применить ключами дверь", "применить", []string{"ключи", "дверь"}},
+		{"взять старый рюкзак", "взять", []string{"старый рюкзак"}},
+		{"взять старого рюкзака", "взять", []string{"старый рюкзак"}},
+		{"взять «старый   рюкзак»", "взять", []string{"старый рюкзак"}},
+		{`взять "синий зонт"`, "взять", []string{"синий зонт"}},
+		{"взять чаю", "взять", []string{"чай"}},
+		{"взять чайник", "взять", []string{"чайник"}},
+		{"взять телефон", "взять", []string{"телефон"}},
+		{"осмотреться", "осмотреться", []string{}},
+		{"", "", nil},
+	}
+	for _, c := range cases {
+		cmd, err := p.Parse(c.line)
+		if err != nil {
+			t.Errorf("%q: %v", c.line, err)
+			continue
+		}
+		if cmd.Verb != c.verb || !reflect.DeepEqual(cmd.Args, c.args) {
+			t.Errorf("%q\n\tresult:   %s %q\n\texpected: %s %q", c.line, cmd.Verb, cmd.Args, c.verb, c.args)
+		}
+	}
+
+	cmd, _ := p.Parse("применить ключи к двери")
+	if !reflect.DeepEqual(cmd.Preps, []string{"к"}) {
+		t.Errorf("preps = %q", cmd.Preps)
+	}
+
+	cmd, _ = p.Parse("сказать на кухне: иди в комнату")
+	if tail := cmd.Tail(1); tail != "иди в комнату" {
+		t.Errorf("tail = %q", tail)
+	}
+
+	if _, err := p.Parse("взять «старый рюкзак"); err != ErrQuote {
+		t.Errorf("expected ErrQuote, got %v", err)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	p := New([]string{"ключи", "дверь", "старый рюкзак"})
+	p.Alias("keys", "ключи")
+	p.Alias("door", "дверь")
+	p.Alias("old backpack", "старый рюкзак")
+
+	cases := map[string][]string{
+		"use keys on the door": {"ключи", "дверь"},
+		"take old backpack":    {"старый рюкзак"},
+		"взять ключи":          {"ключи"},
+		"take the keys":        {"ключи"},
+		"отметка a":            {"a"},
+		"сохранить the":        {"the"},
+	}
+	for line, want := range cases {
+		cmd, _ := p.Parse(line)
+		if !reflect.DeepEqual(cmd.Args, want) {
+			t.Errorf("%q\n\tresult:   %q\n\texpected: %q", line, cmd.Args, want)
+		}
+	}
+}
+
+// названия отдаются так, как записаны в мире, а узнаются в любом регистре
+func TestParseCase(t *testing.T) {
+	p := New([]string{"Кухня", "Коридор", "Старый рюкзак"})
+
+	cases := []struct {
+		line string
+		args []string
+	}{
+		{"идти в коридор", []string{"Коридор"}},
+		{"идти на Кухню", []string{"Кухня"}},
+		{`осмотреть "Старый рюкзак"`, []string{"Старый рюкзак"}},
+		{"осмотреть старый рюкзак", []string{"Старый рюкзак"}},
+	}
+	for _, c := range cases {
+		cmd, _ := p.Parse(c.line)
+		if !reflect.DeepEqual(cmd.Args, c.args) {
+			t.Errorf("%q\n\tresult:   %q\n\texpected: %q", c.line, cmd.Args, c.args)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	for _, words := range [][]string{
+		{"комната", "комнату", "комнате", "комнатой"},
+		{"дверь", "двери", "дверью"},
+		{"ключи", "ключами", "ключ"},
+		{"рюкзак", "рюкзака", "рюкзаке"},
+	} {
+		for _, w := range words[1:] {
+			if Stem(w) != Stem(words[0]) {
+				t.Errorf("Stem(%q) = %q, Stem(%q) = %q", w, Stem(w), words[0], Stem(words[0]))
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package quest
+
+import "github.com/Keniden/vk-homework/game/rules"
+
+/*
+	задание - список целей; цель выполнена, когда игрок хоть раз оказался в условиях If,
+	и дальше считается выполненной.
+	в ordered задании цель засчитывается, только когда выполнены все предыдущие.
+	пока задание не выполнено, в комнатах Rooms (пусто - во всех) "осмотреться" напоминает,
+	что осталось сделать: "надо собрать рюкзак и идти в универ."
+*/
+
+type Objective struct {
+	ID   string     `json:"id"`
+	Text string     `json:"text"`
+	If   rules.Cond `json:"if"`
+}
+
+type Quest struct {
+	ID         string       `json:"id"`
+	Intro      string       `json:"intro"`
+	Rooms      []string     `json:"rooms"`
+	Ordered    bool         `json:"ordered"`
+	Objectives []*Objective `json:"objectives"`
+	Win        string       `json:"win"`
+}
+
+// Key - под каким именем выполненная цель хранится у игрока
+func (q *Quest) Key(o *Objective) string {
+	return q.ID + "/" + o.ID
+}
+
+func (q *Quest) Complete(done map[string]bool) bool {
+	for _, o := range q.Objectives {
+		if !done[q.Key(o)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Shown - напоминать ли о задании в комнате room
+func (q *Quest) Shown(room string) bool {
+	if len(q.Rooms) == 0 {
+		return true
+	}
+	for _, r := range q.Rooms {
+		if r == room {
+			return true
+		}
+	}
+	return false
+}
+
+// Left - тексты невыполненных целей по порядку
+func (q *Quest) Left(done map[string]bool) []string {
+	left := make([]string, 0, len(q.Objectives))
+	for _, o := range q.Objectives {
+		if !done[q.Key(o)] {
+			left = append(left, o.Text)
+		}
+	}
+	return left
+}
+
+// Update отмечает в done цели, условия которых выполнены сейчас;
+// возвращает true, если задание только что выполнено целиком
+func (q *Quest) Update(done map[string]bool, match func(rules.Cond) bool) bool {
+	if q.Complete(done) {
+		return false
+	}
+	for _, o := range q.Objectives {
+		key := q.Key(o)
+		if done[key] {
+			continue
+		}
+		if match(o.If) {
+			done[key] = true
+		} else if q.Ordered {
+			break
+		}
+	}
+	return q.Complete(done)
+}
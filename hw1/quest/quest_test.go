@@ -0,0 +1,46 @@
+package quest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+func TestQuest(t *testing.T) {
+	q := &Quest{
+		ID:      "универ",
+		Intro:   "надо",
+		Ordered: true,
+		Objectives: []*Objective{
+			{ID: "рюкзак", Text: "собрать рюкзак", If: rules.Cond{Wearing: "рюкзак"}},
+			{ID: "улица", Text: "идти в универ", If: rules.Cond{Room: "улица"}},
+		},
+	}
+	done := map[string]bool{}
+	// игрок вышел на улицу без рюкзака - в ordered задании это не считается
+	onStreet := func(c rules.Cond) bool { return c.Room == "улица" }
+	if q.Update(done, onStreet) {
+		t.Fatalf("quest should not be complete")
+	}
+	if got := q.Left(done); !reflect.DeepEqual(got, []string{"собрать рюкзак", "идти в универ"}) {
+		t.Fatalf("left = %q", got)
+	}
+
+	wearing := func(c rules.Cond) bool { return c.Wearing == "рюкзак" }
+	if q.Update(done, wearing) {
+		t.Fatalf("quest should not be complete")
+	}
+	if got := q.Left(done); !reflect.DeepEqual(got, []string{"идти в универ"}) {
+		t.Fatalf("left = %q", got)
+	}
+	if !q.Update(done, onStreet) {
+		t.Fatalf("quest should be complete")
+	}
+	if q.Update(done, onStreet) {
+		t.Fatalf("complete quest should not be reported twice")
+	}
+	if got := q.Left(done); len(got) != 0 {
+		t.Fatalf("left = %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const historyCommand = "история"
+
+// REPL - игра построчно через stdin/stdout.
+// В режиме Script приглашения нет, а каждая команда печатается перед ответом,
+// так что вывод можно сохранить как транскрипт для testdata/transcripts
+type REPL struct {
+	Game   *Game
+	Player string
+	Script bool
+
+	history []string
+}
+
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	sc := bufio.NewScanner(in)
+	for {
+		if !r.Script {
+			w.WriteString(prompt)
+			w.Flush()
+		}
+		if !sc.Scan() {
+			return sc.Err()
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || r.Script && strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line, err := r.expand(line)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			continue
+		}
+		if r.Script {
+			fmt.Fprintln(w, prompt+line)
+		}
+		switch frontend(line) {
+		case quitCommand:
+			fmt.Fprintln(w, r.Game.Text(r.Player, "bye"))
+			return nil
+		case historyCommand:
+			for i, cmd := range r.history {
+				fmt.Fprintf(w, "%d %s\n", i+1, cmd)
+			}
+			continue
+		}
+		r.history = append(r.history, line)
+
+		fmt.Fprintln(w, r.Game.Handle(r.Player, line))
+		for _, msg := range r.Game.Messages(r.Player) {
+			fmt.Fprintln(w, "* "+msg)
+		}
+	}
+}
+
+// expand подставляет команду из истории: "!!" - последнюю, "!N" - N-ю
+func (r *REPL) expand(line string) (string, error) {
+	if !strings.HasPrefix(line, "!") {
+		return line, nil
+	}
+	if len(r.history) == 0 {
+		return "", errors.New(r.Game.Text(r.Player, "history.no"))
+	}
+	if line == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", errors.New(r.Game.Text(r.Player, "history.n", line[1:]))
+	}
+	return r.history[n-1], nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	g := newTestGame(t, "вася")
+	r := &REPL{Game: g, Player: "вася"}
+
+	in := strings.Join([]string{
+		"идти коридор",
+		"",
+		"идти комната",
+		"!1",
+		"!7",
+		"история",
+		"!!",
+		"выход",
+		"осмотреться",
+	}, "\n")
+	var out bytes.Buffer
+	if err := r.Run(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "> ничего интересного. можно пройти - кухня, комната, улица\n" +
+		"> > ты в своей комнате. можно пройти - коридор\n" +
+		"> ничего интересного. можно пройти - кухня, комната, улица\n" +
+		"> нет команды 7 в истории\n" +
+		"> 1 идти коридор\n2 идти комната\n3 идти коридор\n" +
+		"> нет пути в коридор\n" +
+		"> до встречи\n"
+	if out.String() != want {
+		t.Errorf("result:\n%s\nexpected:\n%s", out.String(), want)
+	}
+}
+
+// служебные команды консоли есть и по-английски
+func TestREPLEnglish(t *testing.T) {
+	g := newTestGame(t, "вася")
+	r := &REPL{Game: g, Player: "вася"}
+
+	in := "lang en\nhistory\nquit\nlook\n"
+	var out bytes.Buffer
+	if err := r.Run(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "> language: en\n> 1 lang en\n> see you\n"
+	if out.String() != want {
+		t.Errorf("result:\n%s\nexpected:\n%s", out.String(), want)
+	}
+}
+
+func TestREPLScript(t *testing.T) {
+	g := newTestGame(t, "вася")
+	r := &REPL{Game: g, Player: "вася", Script: true}
+
+	in := "# из кухни в коридор\nидти коридор\nидти улица\n"
+	var out bytes.Buffer
+	if err := r.Run(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "> идти коридор\nничего интересного. можно пройти - кухня, комната, улица\n" +
+		"> идти улица\nдверь закрыта\n"
+	if out.String() != want {
+		t.Errorf("result:\n%s\nexpected:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,20 @@
+package room
+
+// Door - дверь между комнатами; двусторонний проход делит одну дверь на оба выхода
+type Door struct {
+	ID     string
+	Name   string
+	Locked bool
+	Key    string
+}
+
+// Exit - выход из комнаты; Label - как выход называется в этой комнате
+type Exit struct {
+	Label string
+	To    *Room
+	Door  *Door
+}
+
+func (e *Exit) Closed() bool {
+	return e.Door != nil && e.Door.Locked
+}
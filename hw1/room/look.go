@@ -0,0 +1,113 @@
+package room
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplate - описание комнаты, если в мире для неё не задан свой шаблон
+const DefaultTemplate = "{{.Desc}}, {{.Items}}, {{.Mission}}. {{.Others}}. {{.Exits}}"
+
+// Look - что можно вставить в шаблон описания комнаты (text/template):
+// {{.Desc}}, {{.Items}}, {{.Mission}}, {{.Others}}, {{.Exits}},
+// а в условия - {{if .Empty}}, {{if .Flag "свет"}}, {{if .Has "ключи"}}, {{if .Wearing "рюкзак"}}.
+// Пустые части можно не обходить условиями: лишние запятые и точки убирает Tidy
+type Look struct {
+	Desc    string
+	Items   string
+	Mission string
+	Others  string
+	Exits   string
+	Empty   bool
+
+	scene *Scene
+}
+
+func (l Look) Flag(name string) bool {
+	return l.scene != nil && l.scene.Room.Flags[name]
+}
+
+func (l Look) Has(item string) bool {
+	return l.scene != nil && l.scene.Who.Has(item)
+}
+
+func (l Look) Wearing(item string) bool {
+	return l.scene != nil && l.scene.Who.Wearing(item)
+}
+
+// ParseTemplate разбирает шаблон описания
+func ParseTemplate(src string) (*template.Template, error) {
+	return template.New("look").Option("missingkey=error").Parse(src)
+}
+
+// Render собирает описание по шаблону t
+func (l Look) Render(t *template.Template) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, l); err != nil {
+		return "", err
+	}
+	return Tidy(b.String()), nil
+}
+
+// Compile - разобранный шаблон src; комната разбирает каждый свой шаблон один раз,
+// мир делает это при загрузке для всех переводов
+func (r *Room) Compile(src string) (*template.Template, error) {
+	if t, ok := r.looks[src]; ok {
+		return t, nil
+	}
+	t, err := ParseTemplate(src)
+	if err != nil {
+		return nil, err
+	}
+	if r.looks == nil {
+		r.looks = make(map[string]*template.Template)
+	}
+	r.looks[src] = t
+	return t, nil
+}
+
+// Describe - описание комнаты из сцены осмотра по шаблону src; условия шаблона проверяются для s.Who
+func (s *Scene) Describe(src string, l Look) (string, error) {
+	l.scene = s
+	t, err := s.Room.Compile(src)
+	if err != nil {
+		return "", err
+	}
+	return l.Render(t)
+}
+
+var (
+	spaces = regexp.MustCompile(`\s+`)
+	// знаки препинания вместе с пробелами вокруг - то, что остаётся между частями описания
+	punctRun = regexp.MustCompile(`[ ,.]*[,.][ ,.]*`)
+)
+
+// Tidy расставляет знаки после подстановки: пробелы схлопываются, от пустых частей
+// не остаётся ", ," и ". .", а запятая рядом с точкой становится точкой.
+// Многоточие из текста мира остаётся как есть.
+// в начале знаки препинания убираются, в конце - только запятая
+func Tidy(s string) string {
+	s = spaces.ReplaceAllString(s, " ")
+	s = punctRun.ReplaceAllStringFunc(s, tidyRun)
+	s = strings.TrimLeft(s, " ")
+	if !strings.HasPrefix(s, "...") {
+		s = strings.TrimLeft(s, " ,.")
+	}
+	return strings.TrimRight(s, " ,")
+}
+
+// tidyRun оставляет от знаков между частями один: многоточие, точку или запятую
+func tidyRun(run string) string {
+	p := ","
+	switch {
+	case strings.Contains(run, "..."):
+		p = "..."
+	case strings.Contains(run, "."):
+		p = "."
+	}
+	if strings.HasSuffix(run, " ") {
+		p += " "
+	}
+	return p
+}
@@ -0,0 +1,70 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/Keniden/vk-homework/game/msg"
+)
+
+func TestTidy(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"ты находишься на кухне, , на столе: чай, надо идти в универ.. . можно пройти - коридор",
+			"ты находишься на кухне, на столе: чай, надо идти в универ. можно пройти - коридор"},
+		{", на столе: ключи, . . можно пройти - коридор", "на столе: ключи. можно пройти - коридор"},
+		{"пустая комната., надо идти", "пустая комната. надо идти"},
+		{"  темно  ,   тихо ,", "темно, тихо"},
+		{"тишина... , на столе: ничего", "тишина... на столе: ничего"},
+		{"...и тишина. можно пройти - коридор...", "...и тишина. можно пройти - коридор..."},
+		{"на столе: чай, ждать.... можно пройти", "на столе: чай, ждать... можно пройти"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := Tidy(c.in); got != c.want {
+			t.Errorf("%q\n\tresult:   %s\n\texpected: %s", c.in, got, c.want)
+		}
+	}
+}
+
+type actor struct{ worn string }
+
+func (a actor) Nick() string             { return "вася" }
+func (a actor) Notify(msg.Text)          {}
+func (a actor) Has(item string) bool     { return item == a.worn }
+func (a actor) Wearing(item string) bool { return item == a.worn }
+
+func TestDescribe(t *testing.T) {
+	r := NewRoom("чулан", "", "", "", nil)
+	src := `{{if .Flag "свет"}}{{.Items}}{{else}}темно{{end}}, {{if .Wearing "фонарик"}}фонарик светит{{end}}. {{.Exits}}`
+	look := Look{Items: "на полке: банка", Exits: "можно пройти - коридор"}
+
+	sc := &Scene{Room: r, Who: actor{}}
+	if got, _ := sc.Describe(src, look); got != "темно. можно пройти - коридор" {
+		t.Errorf("dark: %s", got)
+	}
+	sc.Who = actor{worn: "фонарик"}
+	if got, _ := sc.Describe(src, look); got != "темно, фонарик светит. можно пройти - коридор" {
+		t.Errorf("torch: %s", got)
+	}
+	r.SetFlag("свет", true)
+	if got, _ := sc.Describe(src, look); got != "на полке: банка, фонарик светит. можно пройти - коридор" {
+		t.Errorf("light: %s", got)
+	}
+
+	if t1, _ := r.Compile(src); t1 != r.looks[src] || len(r.looks) != 1 {
+		t.Errorf("template is parsed again")
+	}
+
+	bad, err := ParseTemplate("{{.Colour}}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := (Look{}).Render(bad); err == nil {
+		t.Errorf("expected error for unknown field")
+	}
+	if _, err := sc.Describe("{{if}}", look); err == nil {
+		t.Errorf("expected parse error")
+	}
+}
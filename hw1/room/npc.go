@@ -0,0 +1,38 @@
+package room
+
+import "github.com/Keniden/vk-homework/game/rules"
+
+// NPC - персонаж комнаты; разговор с ним идёт по репликам Nodes, начиная со Start
+type NPC struct {
+	Name  string
+	Desc  string
+	Start string
+	Nodes map[string]*Node
+}
+
+// Node - реплика персонажа и ответы, которые на неё можно дать
+type Node struct {
+	Text    string
+	Choices []*Choice
+}
+
+// Choice - ответ игрока: виден, только если выполнено If,
+// Take - предмет, который игрок отдаёт, Reply - что персонаж скажет в ответ,
+// Next - следующая реплика, пусто - разговор окончен
+type Choice struct {
+	Say     string         `json:"say"`
+	If      rules.Cond     `json:"if"`
+	Take    string         `json:"take"`
+	Effects []rules.Effect `json:"effects"`
+	Reply   string         `json:"reply"`
+	Next    string         `json:"next"`
+}
+
+func (r *Room) NPC(name string) *NPC {
+	for _, n := range r.NPCs {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
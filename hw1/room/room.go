@@ -1,48 +1,130 @@
 package room
 
-import "github.com/Keniden/vk-homework/game/item"
+import (
+	"text/template"
+
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/msg"
+)
+
+// Visitor - тот, кто находится в комнате и слышит, что в ней происходит;
+// сообщение переводится на язык того, кто его слышит
+type Visitor interface {
+	Nick() string
+	Notify(t msg.Text)
+}
 
 type Room struct {
+	ID       string
 	Name     string
 	LookDesc string
-	GoDesc   string
+	// Template - шаблон описания при осмотре (см. Look), пусто - DefaultTemplate
+	Template    string
+	GoDesc      string
 	MissionText string
-	Items    []*item.Item
-	ToGO     []*Room
-	Backpack bool
-	IsHall   bool
-	Door     bool
+	Items       []*item.Item
+	Surfaces    []*Surface
+	Exits       []*Exit
+	NPCs        []*NPC
+	Flags       map[string]bool
+	Triggers    map[Event][]Trigger
+	Visitors    []Visitor
+
+	// looks - разобранные шаблоны описания по тексту шаблона
+	looks map[string]*template.Template
 }
 
 func NewRoom(Name string, LookDesc string, GoDesc string, MissionText string, Items []*item.Item) *Room {
 	return &Room{
-		Name:     Name,
-		LookDesc: LookDesc,
-		GoDesc:   GoDesc,
+		ID:          Name,
+		Name:        Name,
+		LookDesc:    LookDesc,
+		GoDesc:      GoDesc,
 		MissionText: MissionText,
-		Items:    make([]*item.Item, 0),
+		Items:       make([]*item.Item, 0),
+		Surfaces:    []*Surface{Table()},
+		Flags:       make(map[string]bool),
 	}
 }
 
 func (r *Room) AddItem(item1 string) {
-	newItem := &item.Item{Name: item1}
-	r.Items = append(r.Items, newItem)
+	r.PutItem(item.New(item1))
+}
+
+// PutItem кладёт предмет на его место, а если в комнате такого места нет - на первое
+func (r *Room) PutItem(it *item.Item) {
+	if r.Surface(it.Place) == nil && len(r.Surfaces) > 0 {
+		it.Place = r.Surfaces[0].ID
+	}
+	r.Items = append(r.Items, it)
 }
 
+// AddRout добавляет проход без двери, названный по имени комнаты
 func (r *Room) AddRout(add *Room) {
-	r.ToGO = append(r.ToGO, add)
+	r.AddExit(&Exit{Label: add.Name, To: add})
+}
+
+func (r *Room) AddExit(e *Exit) {
+	r.Exits = append(r.Exits, e)
+}
+
+func (r *Room) Exit(label string) *Exit {
+	for _, e := range r.Exits {
+		if e.Label == label {
+			return e
+		}
+	}
+	return nil
 }
 
-func (r *Room) AddBackpack() {
-	r.Backpack = true
+// Door ищет дверь по названию среди выходов комнаты
+func (r *Room) Door(name string) *Door {
+	for _, e := range r.Exits {
+		if e.Door != nil && e.Door.Name == name {
+			return e.Door
+		}
+	}
+	return nil
+}
+
+func (r *Room) ExitLabels() []string {
+	res := make([]string, 0, len(r.Exits))
+	for _, e := range r.Exits {
+		res = append(res, e.Label)
+	}
+	return res
+}
+
+func (r *Room) Empty() bool {
+	return len(r.Items) == 0
 }
 
-func (r *Room) ItHall() {
-	r.IsHall = true
+func (r *Room) SetFlag(flag string, value bool) {
+	if value {
+		r.Flags[flag] = true
+		return
+	}
+	delete(r.Flags, flag)
+}
+
+func (r *Room) Enter(v Visitor) {
+	r.Visitors = append(r.Visitors, v)
+}
+
+func (r *Room) Leave(v Visitor) {
+	for idx, in := range r.Visitors {
+		if in == v {
+			r.Visitors = append(r.Visitors[:idx], r.Visitors[idx+1:]...)
+			return
+		}
+	}
 }
 
-func (r *Room) UnlockDoor() {
-	if r.IsHall {
-		r.Door = true
+// Announce сообщает всем в комнате, кроме from
+func (r *Room) Announce(from Visitor, t msg.Text) {
+	for _, v := range r.Visitors {
+		if v != from {
+			v.Notify(t)
+		}
 	}
 }
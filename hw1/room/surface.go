@@ -0,0 +1,36 @@
+package room
+
+import "github.com/Keniden/vk-homework/game/item"
+
+// Surface - место в комнате, где лежат предметы: стол, стул, полка, пол.
+// Name - как оно звучит в описании ("на полке"), Always - показывать его и пустым ("на столе: ничего")
+type Surface struct {
+	ID     string
+	Name   string
+	Always bool
+}
+
+// Table - стол, который есть в комнате, если в мире для неё не описано других мест
+func Table() *Surface {
+	return &Surface{ID: "стол", Name: "на столе", Always: true}
+}
+
+func (r *Room) Surface(id string) *Surface {
+	for _, s := range r.Surfaces {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// ItemsOn - предметы на месте s; то, что лежит неизвестно где, считается лежащим на первом месте комнаты
+func (r *Room) ItemsOn(s *Surface) []*item.Item {
+	var res []*item.Item
+	for _, it := range r.Items {
+		if it.Place == s.ID || s == r.Surfaces[0] && r.Surface(it.Place) == nil {
+			res = append(res, it)
+		}
+	}
+	return res
+}
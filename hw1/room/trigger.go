@@ -0,0 +1,71 @@
+package room
+
+import "github.com/Keniden/vk-homework/game/rules"
+
+type Event string
+
+const (
+	OnEnter Event = "enter"
+	OnLook  Event = "look"
+	OnTake  Event = "take"
+	OnUse   Event = "use"
+	OnLeave Event = "leave"
+)
+
+// Actor - игрок, с которым происходит событие
+type Actor interface {
+	Visitor
+	Has(item string) bool
+	Wearing(item string) bool
+}
+
+// Scene - событие в комнате; триггеры могут поменять Desc и Mission,
+// добавить реплики в Say, эффекты в Effects или отменить действие через Refuse
+type Scene struct {
+	Room   *Room
+	Who    Actor
+	Item   string
+	Target string
+
+	Desc    string
+	Mission string
+	Say     []string
+	Effects []rules.Effect
+	Refuse  string
+}
+
+type Trigger func(s *Scene)
+
+func (r *Room) On(e Event, t Trigger) {
+	if r.Triggers == nil {
+		r.Triggers = make(map[Event][]Trigger)
+	}
+	r.Triggers[e] = append(r.Triggers[e], t)
+}
+
+// Fire запускает триггеры события по порядку, пока какой-нибудь не отменит действие
+func (r *Room) Fire(e Event, s *Scene) {
+	s.Room = r
+	for _, t := range r.Triggers[e] {
+		t(s)
+		if s.Refuse != "" {
+			return
+		}
+	}
+}
+
+func (s *Scene) Match(c rules.Cond) bool {
+	r := s.Room
+	switch {
+	case c.Room != "" && c.Room != r.ID,
+		c.Flag != "" && !r.Flags[c.Flag],
+		c.NoFlag != "" && r.Flags[c.NoFlag],
+		c.Item != "" && c.Item != s.Item,
+		c.Target != "" && c.Target != s.Target,
+		c.Has != "" && !s.Who.Has(c.Has),
+		c.Wearing != "" && !s.Who.Wearing(c.Wearing),
+		c.Empty && !r.Empty():
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package rules
+
+/*
+	таблица взаимодействий: "применить <Item> <Target>"
+	правило срабатывает, если выполнено условие If,
+	и применяет к миру эффекты Effects
+*/
+
+// Cond - условие на комнату, в которой стоит игрок, и на самого игрока;
+// пустое условие выполняется всегда.
+// Item и Target - предметы из команды (для триггеров take и use), Empty - в комнате ничего не лежит
+type Cond struct {
+	Room    string `json:"room,omitempty"`
+	Flag    string `json:"flag,omitempty"`
+	NoFlag  string `json:"no_flag,omitempty"`
+	Item    string `json:"item,omitempty"`
+	Target  string `json:"target,omitempty"`
+	Has     string `json:"has,omitempty"`
+	Wearing string `json:"wearing,omitempty"`
+	Empty   bool   `json:"empty,omitempty"`
+}
+
+// Effect - изменение мира; Room - ID комнаты, по умолчанию та, где стоит игрок,
+// Unlock и Lock - ID дверей, Schedule - ID события, которое случится через In ходов,
+// Cancel - ID события, которое больше не случится
+type Effect struct {
+	Room     string `json:"room,omitempty"`
+	Set      string `json:"set,omitempty"`
+	Unset    string `json:"unset,omitempty"`
+	Unlock   string `json:"unlock,omitempty"`
+	Lock     string `json:"lock,omitempty"`
+	Spawn    string `json:"spawn,omitempty"`
+	Remove   string `json:"remove,omitempty"`
+	Give     string `json:"give,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
+	In       int    `json:"in,omitempty"`
+	Cancel   string `json:"cancel,omitempty"`
+}
+
+// Event - то, что случается само через некоторое число ходов:
+// At - на каком ходу в первый раз (0 - только когда запланирует эффект), Every - повторять через столько ходов,
+// Room - где это происходит: там применяются эффекты и слышно Message, пусто - слышно всем
+type Event struct {
+	ID      string   `json:"id"`
+	At      int      `json:"at"`
+	Every   int      `json:"every"`
+	Room    string   `json:"room"`
+	Message string   `json:"message"`
+	Effects []Effect `json:"effects"`
+}
+
+type Rule struct {
+	Item    string   `json:"item"`
+	Target  string   `json:"target"`
+	If      Cond     `json:"if"`
+	Effects []Effect `json:"effects"`
+	Message string   `json:"message"`
+	Consume bool     `json:"consume"`
+}
+
+type Table []*Rule
+
+// Lookup отдаёт правила для пары предмет-цель в порядке объявления
+func (t Table) Lookup(item, target string) []*Rule {
+	var res []*Rule
+	for _, r := range t {
+		if r.Item == item && r.Target == target {
+			res = append(res, r)
+		}
+	}
+	return res
+}
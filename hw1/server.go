@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	prompt      = "> "
+	quitCommand = "выход"
+)
+
+// Server - построчный tcp фронтенд к игре, к нему можно подключиться через nc или telnet
+type Server struct {
+	Game        *Game
+	IdleTimeout time.Duration
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	defer l.Close()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+type input struct {
+	line string
+	err  error
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	lines := make(chan input)
+	done := make(chan struct{})
+	defer close(done)
+	go s.readLines(conn, lines, done)
+
+	name, ok := s.login(conn, lines)
+	if !ok {
+		return
+	}
+	defer s.Game.Leave(name)
+
+	wake := s.Game.Subscribe(name)
+	write(conn, s.Game.Look(name)+"\r\n"+prompt)
+
+	for {
+		select {
+		case in := <-lines:
+			if in.err != nil {
+				s.closeWith(conn, name, in.err)
+				return
+			}
+			if frontend(in.line) == quitCommand {
+				write(conn, s.Game.Text(name, "bye")+"\r\n")
+				return
+			}
+			out := ""
+			if in.line != "" {
+				out = s.Game.Handle(name, in.line) + "\r\n"
+			}
+			write(conn, out+s.pending(name)+prompt)
+		case <-wake:
+			if msgs := s.pending(name); msgs != "" {
+				write(conn, "\r\n"+msgs+prompt)
+			}
+		}
+	}
+}
+
+func (s *Server) login(conn net.Conn, lines <-chan input) (string, bool) {
+	write(conn, s.Game.Text("", "login")+"\r\n"+prompt)
+	for in := range lines {
+		if in.err != nil {
+			s.closeWith(conn, "", in.err)
+			return "", false
+		}
+		if frontend(in.line) == quitCommand {
+			return "", false
+		}
+		if _, err := s.Game.Join(in.line); err != nil {
+			write(conn, err.Error()+"\r\n"+prompt)
+			continue
+		}
+		return in.line, true
+	}
+	return "", false
+}
+
+func (s *Server) readLines(conn net.Conn, lines chan<- input, done <-chan struct{}) {
+	send := func(in input) bool {
+		select {
+		case lines <- in:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		if s.IdleTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		raw, err := r.ReadBytes('\n')
+		if len(raw) > 0 && !send(input{line: cleanLine(raw)}) {
+			return
+		}
+		if err != nil {
+			send(input{err: err})
+			return
+		}
+	}
+}
+
+func (s *Server) closeWith(conn net.Conn, name string, err error) {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		write(conn, "\r\n"+s.Game.Text(name, "idle")+"\r\n")
+	}
+}
+
+func (s *Server) pending(name string) string {
+	msgs := s.Game.Messages(name)
+	if len(msgs) == 0 {
+		return ""
+	}
+	return strings.Join(msgs, "\r\n") + "\r\n"
+}
+
+func write(conn net.Conn, text string) {
+	if _, err := io.WriteString(conn, text); err != nil {
+		fmt.Fprintln(os.Stderr, "write:", err)
+	}
+}
+
+const (
+	telnetIAC  = 255
+	telnetSB   = 250
+	telnetSE   = 240
+	telnetWILL = 251
+	telnetDONT = 254
+)
+
+// cleanLine убирает из строки управляющие последовательности telnet,
+// применяет backspace, отрезает \r\n и выкидывает битый utf-8
+func cleanLine(raw []byte) string {
+	buf := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == telnetIAC && i+1 < len(raw):
+			cmd := raw[i+1]
+			switch {
+			case cmd == telnetIAC:
+				i++
+			case cmd >= telnetWILL && cmd <= telnetDONT:
+				i += 2
+			case cmd == telnetSB:
+				for i < len(raw) && !(raw[i] == telnetIAC && i+1 < len(raw) && raw[i+1] == telnetSE) {
+					i++
+				}
+				i++
+			default:
+				i++
+			}
+		case c == '\b' || c == 0x7f:
+			if len(buf) > 0 {
+				_, size := utf8.DecodeLastRune(buf)
+				buf = buf[:len(buf)-size]
+			}
+		case c == '\r' || c == '\n':
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(string(buf), ""))
+}
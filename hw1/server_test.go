@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanLine(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{"осмотреться\r\n", "осмотреться"},
+		{"  идти   коридор \n", "идти   коридор"},
+		{"\xff\xfb\x01взять ключи\n", "взять ключи"},
+		{"взять ключм\bи\n", "взять ключи"},
+		{"идти\x7f\x7f\x7f\x7fидти кухня\n", "идти кухня"},
+		{"чай\xd0\n", "чай"},
+	}
+	for _, c := range cases {
+		if got := cleanLine([]byte(c.raw)); got != c.want {
+			t.Errorf("cleanLine(%q) = %q, want %q", c.raw, got, c.want)
+		}
+	}
+}
+
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// readUntilPrompt читает ответ сервера до приглашения ввода
+func (c *testConn) readUntilPrompt() string {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var sb strings.Builder
+	for !strings.HasSuffix(sb.String(), prompt) {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			c.t.Fatalf("read: %v (got %q)", err, sb.String())
+		}
+		sb.WriteByte(b)
+	}
+	return strings.TrimSuffix(sb.String(), prompt)
+}
+
+func (c *testConn) send(line string) string {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(line + "\r\n")); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+	return c.readUntilPrompt()
+}
+
+func dial(t *testing.T, addr string) *testConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &testConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+	c.readUntilPrompt()
+	return c
+}
+
+func TestServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &Server{Game: newTestGame(t), IdleTimeout: time.Second}
+	go s.Serve(l)
+	t.Cleanup(func() { l.Close() })
+
+	vasya := dial(t, l.Addr().String())
+	if got := vasya.send("вася"); !strings.HasPrefix(got, "ты находишься на кухне") {
+		t.Fatalf("after login: %q", got)
+	}
+
+	petya := dial(t, l.Addr().String())
+	if got := petya.send("вася"); got != "игрок вася уже в игре\r\n" {
+		t.Fatalf("duplicate login: %q", got)
+	}
+	petya.send("петя")
+
+	if got := vasya.readUntilPrompt(); got != "\r\nпетя вошёл в игру\r\n" {
+		t.Fatalf("notification: %q", got)
+	}
+	if got := vasya.send("идти коридор"); got != "ничего интересного. можно пройти - кухня, комната, улица\r\n" {
+		t.Fatalf("go: %q", got)
+	}
+	// вход в игру хода не тратит
+	if got := vasya.send("время"); got != "прошёл 1 ход\r\n" {
+		t.Fatalf("time: %q", got)
+	}
+
+	if got := petya.readUntilPrompt(); got != "\r\nвася ушёл в коридор\r\n" {
+		t.Fatalf("go notification: %q", got)
+	}
+
+	if _, err := vasya.conn.Write([]byte("quit\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, err := vasya.r.ReadString('\n'); err != nil || got != "до встречи\r\n" {
+		t.Fatalf("quit: %q %v", got, err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		s.Game.mu.Lock()
+		_, online := s.Game.Players["вася"]
+		s.Game.mu.Unlock()
+		if !online {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("вася is still in game after quit")
+		}
+	}
+	if got := petya.send("идти коридор"); got != "ничего интересного. можно пройти - кухня, комната, улица\r\n" {
+		t.Fatalf("after quit: %q", got)
+	}
+	if got := petya.send("осмотреться"); strings.Contains(got, "вася") {
+		t.Fatalf("вася should have left: %q", got)
+	}
+}
@@ -0,0 +1,231 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Keniden/vk-homework/game/clock"
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/user"
+	"github.com/Keniden/vk-homework/game/world"
+)
+
+/*
+	снимок изменяемого состояния мира и игроков
+	комнаты ссылаются друг на друга указателями (и по кругу),
+	поэтому в снимке вместо указателей - ID комнат
+*/
+
+type Room struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	LookDesc    string       `json:"look,omitempty"`
+	GoDesc      string       `json:"go,omitempty"`
+	MissionText string       `json:"mission,omitempty"`
+	Items       []*item.Item `json:"items"`
+	Exits       []Exit       `json:"exits"`
+	Flags       []string     `json:"flags,omitempty"`
+}
+
+type Exit struct {
+	Label string `json:"label"`
+	To    string `json:"to"`
+	Door  string `json:"door,omitempty"`
+}
+
+type Door struct {
+	ID     string `json:"id"`
+	Locked bool   `json:"locked"`
+}
+
+type Player struct {
+	Name string       `json:"name"`
+	Room string       `json:"room"`
+	Worn []*item.Item `json:"worn"`
+	Done []string     `json:"done,omitempty"`
+	Talk *Talk        `json:"talk,omitempty"`
+}
+
+// Talk - разговор, на котором остановился игрок
+type Talk struct {
+	NPC  string `json:"npc"`
+	Node string `json:"node"`
+}
+
+type Snapshot struct {
+	Start   string          `json:"start"`
+	Turn    int             `json:"turn"`
+	Pending []clock.Pending `json:"pending,omitempty"`
+	Rooms   []Room          `json:"rooms"`
+	Doors   []Door          `json:"doors"`
+	Players []Player        `json:"players"`
+}
+
+func Capture(w *world.World, players []*user.User) *Snapshot {
+	s := &Snapshot{
+		Start:   w.Start.ID,
+		Turn:    w.Clock.Turn,
+		Pending: append([]clock.Pending(nil), w.Clock.Pending...),
+		Rooms:   make([]Room, 0, len(w.Rooms)),
+	}
+	for _, r := range w.Rooms {
+		exits := make([]Exit, 0, len(r.Exits))
+		for _, e := range r.Exits {
+			es := Exit{Label: e.Label, To: e.To.ID}
+			if e.Door != nil {
+				es.Door = e.Door.ID
+			}
+			exits = append(exits, es)
+		}
+		s.Rooms = append(s.Rooms, Room{
+			ID:          r.ID,
+			Name:        r.Name,
+			LookDesc:    r.LookDesc,
+			GoDesc:      r.GoDesc,
+			MissionText: r.MissionText,
+			Items:       item.CloneAll(r.Items),
+			Exits:       exits,
+			Flags:       flags(r.Flags),
+		})
+	}
+	for _, d := range w.Doors {
+		s.Doors = append(s.Doors, Door{ID: d.ID, Locked: d.Locked})
+	}
+	for _, u := range players {
+		ps := Player{
+			Name: u.Name,
+			Room: u.InPlace.ID,
+			Worn: item.CloneAll(u.Worn),
+			Done: flags(u.Done),
+		}
+		if npc, node := u.Conversation(); npc != "" {
+			ps.Talk = &Talk{NPC: npc, Node: node}
+		}
+		s.Players = append(s.Players, ps)
+	}
+	return s
+}
+
+// Restore возвращает миру w и игрокам состояние из снимка.
+// Мир должен быть загружен из того же файла: правила и прочие описания берутся из него,
+// а из снимка - только то, что меняется во время игры.
+// Игроки, которых нет в снимке, оказываются в стартовой комнате с пустыми руками
+// и без выполненных заданий.
+func (s *Snapshot) Restore(w *world.World, players []*user.User) error {
+	byID := make(map[string]*room.Room, len(w.Rooms))
+	for _, r := range w.Rooms {
+		byID[r.ID] = r
+	}
+	for _, rs := range s.Rooms {
+		if _, ok := byID[rs.ID]; !ok {
+			return fmt.Errorf("unknown room %q", rs.ID)
+		}
+		for _, es := range rs.Exits {
+			if _, ok := byID[es.To]; !ok {
+				return fmt.Errorf("room %q: unknown exit %q", rs.ID, es.To)
+			}
+			if es.Door != "" && w.Door(es.Door) == nil {
+				return fmt.Errorf("room %q: unknown door %q", rs.ID, es.Door)
+			}
+		}
+	}
+	for _, ds := range s.Doors {
+		if w.Door(ds.ID) == nil {
+			return fmt.Errorf("unknown door %q", ds.ID)
+		}
+	}
+	for _, p := range s.Pending {
+		if _, ok := w.Events[p.ID]; !ok {
+			return fmt.Errorf("unknown event %q", p.ID)
+		}
+	}
+	start, ok := byID[s.Start]
+	if !ok {
+		return fmt.Errorf("unknown start room %q", s.Start)
+	}
+	saved := make(map[string]Player, len(s.Players))
+	for _, ps := range s.Players {
+		if _, ok := byID[ps.Room]; !ok {
+			return fmt.Errorf("player %q: unknown room %q", ps.Name, ps.Room)
+		}
+		saved[ps.Name] = ps
+	}
+
+	w.Start = start
+	w.Clock = clock.Clock{Turn: s.Turn, Pending: append([]clock.Pending(nil), s.Pending...)}
+	for _, rs := range s.Rooms {
+		r := byID[rs.ID]
+		r.Name = rs.Name
+		r.LookDesc = rs.LookDesc
+		r.GoDesc = rs.GoDesc
+		r.MissionText = rs.MissionText
+		r.Items = item.CloneAll(rs.Items)
+		r.Exits = make([]*room.Exit, 0, len(rs.Exits))
+		for _, es := range rs.Exits {
+			e := &room.Exit{Label: es.Label, To: byID[es.To]}
+			if es.Door != "" {
+				e.Door = w.Door(es.Door)
+			}
+			r.AddExit(e)
+		}
+		r.Flags = make(map[string]bool, len(rs.Flags))
+		for _, f := range rs.Flags {
+			r.Flags[f] = true
+		}
+	}
+	for _, ds := range s.Doors {
+		w.Door(ds.ID).Locked = ds.Locked
+	}
+	for _, u := range players {
+		u.InPlace.Leave(u)
+		ps, ok := saved[u.Name]
+		if !ok {
+			ps = Player{Room: w.Start.ID}
+		}
+		u.InPlace = byID[ps.Room]
+		u.Worn = item.CloneAll(ps.Worn)
+		u.Done = make(map[string]bool, len(ps.Done))
+		for _, key := range ps.Done {
+			u.Done[key] = true
+		}
+		u.InPlace.Enter(u)
+		if ps.Talk != nil {
+			u.Resume(ps.Talk.NPC, ps.Talk.Node)
+		} else {
+			u.Resume("", "")
+		}
+	}
+	return nil
+}
+
+func Save(path string, s *Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func flags(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for f := range m {
+		res = append(res, f)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+	транскрипт - сценарий игры в текстовом файле:
+
+	# комментарий к следующей команде
+	> осмотреться
+	ты находишься на кухне, ...
+	> идти улица
+	на улице весна. можно пройти - домой
+	* сообщение, пришедшее игроку после команды
+
+	после "> " идёт команда, следующие строки до новой команды - ожидаемый ответ,
+	строки со "* " - сообщения, которые игрок получил после неё
+*/
+
+type Step struct {
+	Comments []string
+	Command  string
+	Answer   string
+	Messages []string
+}
+
+func ParseTranscript(r io.Reader) ([]Step, error) {
+	var (
+		steps    []Step
+		comments []string
+		answer   []string
+	)
+	flush := func() {
+		if len(steps) > 0 {
+			steps[len(steps)-1].Answer = strings.TrimRight(strings.Join(answer, "\n"), "\n")
+		}
+		answer = nil
+	}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "> ") || line == ">":
+			flush()
+			cmd := strings.TrimPrefix(strings.TrimPrefix(line, ">"), " ")
+			steps = append(steps, Step{Comments: comments, Command: cmd})
+			comments = nil
+		case strings.HasPrefix(line, "#"):
+			comments = append(comments, line)
+		case len(steps) == 0:
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("line %d: expected command, got %q", n, line)
+			}
+		case strings.HasPrefix(line, "* "):
+			last := &steps[len(steps)-1]
+			last.Messages = append(last.Messages, strings.TrimPrefix(line, "* "))
+		default:
+			answer = append(answer, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return steps, nil
+}
+
+func FormatTranscript(w io.Writer, steps []Step) error {
+	bw := bufio.NewWriter(w)
+	for i, s := range steps {
+		if i > 0 && len(s.Comments) > 0 {
+			bw.WriteString("\n")
+		}
+		for _, c := range s.Comments {
+			bw.WriteString(c + "\n")
+		}
+		bw.WriteString("> " + s.Command + "\n")
+		if s.Answer != "" {
+			bw.WriteString(s.Answer + "\n")
+		}
+		for _, m := range s.Messages {
+			bw.WriteString("* " + m + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// Replay проигрывает команды сценария в новой игре и возвращает то, что получилось на самом деле
+func Replay(steps []Step) []Step {
+	initGame()
+	game.Messages(defaultPlayer)
+	res := make([]Step, 0, len(steps))
+	for _, s := range steps {
+		res = append(res, Step{
+			Comments: s.Comments,
+			Command:  s.Command,
+			Answer:   handleCommand(s.Command),
+			Messages: game.Messages(defaultPlayer),
+		})
+	}
+	return res
+}
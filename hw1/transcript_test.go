@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// go test -run TestTranscripts -update перезаписывает сценарии тем, что отвечает игра
+var update = flag.Bool("update", false, "rewrite transcripts in testdata with actual answers")
+
+func TestTranscripts(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "transcripts", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no transcripts in testdata/transcripts")
+	}
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".txt"), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ParseTranscript(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			got := Replay(want)
+
+			if *update {
+				var buf bytes.Buffer
+				if err := FormatTranscript(&buf, got); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			for i := range want {
+				w, g := want[i], got[i]
+				if g.Answer != w.Answer {
+					t.Errorf("step %d: > %s\n\tresult:   %s\n\texpected: %s", i+1, w.Command, g.Answer, w.Answer)
+				}
+				if strings.Join(g.Messages, "\n") != strings.Join(w.Messages, "\n") {
+					t.Errorf("step %d: > %s\n\tmessages: %q\n\texpected: %q", i+1, w.Command, g.Messages, w.Messages)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTranscript(t *testing.T) {
+	steps := []Step{
+		{Command: "осмотреться", Answer: "первая строка\nвторая строка"},
+		{Comments: []string{"# выходим"}, Command: "идти улица", Answer: "на улице весна.", Messages: []string{"ура"}},
+		{Command: "", Answer: "неизвестная команда"},
+	}
+	var buf bytes.Buffer
+	if err := FormatTranscript(&buf, steps); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTranscript(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	FormatTranscript(&again, parsed)
+	var orig bytes.Buffer
+	FormatTranscript(&orig, steps)
+	if again.String() != orig.String() {
+		t.Errorf("round trip:\n%s\nexpected:\n%s", again.String(), orig.String())
+	}
+
+	if _, err := ParseTranscript(strings.NewReader("ответ без команды\n")); err == nil {
+		t.Errorf("expected error for answer before first command")
+	}
+}
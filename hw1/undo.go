@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/state"
+	"github.com/Keniden/vk-homework/game/user"
+)
+
+// undoDepth - сколько последних ходов можно отменить
+const undoDepth = 50
+
+// move - ход игрока и мир до него.
+// Ход без снимка ничего не изменил, но часы шли и могли сработать события - это граница, за которую отменять нельзя
+type move struct {
+	player  string
+	command string
+	snap    *state.Snapshot
+}
+
+// checkpoint - отметка игрока; changes - сколько раз мир менялся к моменту отметки
+type checkpoint struct {
+	snap    *state.Snapshot
+	changes int
+}
+
+// changed отмечает, что игрок только что изменил мир
+func (g *Game) changed(gamer *user.User) {
+	g.changes++
+	g.lastChange[gamer.Name] = g.changes
+}
+
+// changedSince - кто из других игроков последним менял мир после изменения номер since
+func (g *Game) changedSince(gamer *user.User, since int) string {
+	who, last := "", since
+	for name, n := range g.lastChange {
+		if name != gamer.Name && n > last {
+			who, last = name, n
+		}
+	}
+	return who
+}
+
+// remember запоминает мир before, каким он был перед командой игрока.
+// Команда, которая ничего не изменила ("дверь закрыта", "нет такого"), сама не отменяется; false - так и вышло
+func (g *Game) remember(gamer *user.User, command string, before *state.Snapshot) bool {
+	if reflect.DeepEqual(before, state.Capture(g.World, g.players())) {
+		return false
+	}
+	g.record(gamer, move{player: gamer.Name, command: command, snap: before})
+	return true
+}
+
+// pass отмечает ход игрока, который мир не изменил: часы всё равно пошли
+func (g *Game) pass(gamer *user.User, command string) {
+	g.record(gamer, move{player: gamer.Name, command: command})
+}
+
+// record добавляет ход в историю, самые старые ходы забываются
+func (g *Game) record(gamer *user.User, m move) {
+	g.changed(gamer)
+	g.history = append(g.history, m)
+	if len(g.history) > undoDepth {
+		g.history = g.history[len(g.history)-undoDepth:]
+	}
+}
+
+// undo возвращает мир к тому, что было перед последним ходом игрока.
+// Мир общий, поэтому отменить можно только ход, после которого никто больше не ходил -
+// даже пустой ход другого игрока двигает часы. Свои пустые ходы отменяются вместе с ним
+func (g *Game) undo(gamer *user.User) string {
+	i := len(g.history) - 1
+	for i >= 0 && g.history[i].player == gamer.Name && g.history[i].snap == nil {
+		i--
+	}
+	if i < 0 {
+		return gamer.T("undo.none")
+	}
+	last := g.history[i]
+	if last.player != gamer.Name {
+		return gamer.T("undo.other", last.player)
+	}
+	if err := last.snap.Restore(g.World, g.players()); err != nil {
+		return gamer.T("load.fail", err)
+	}
+	g.history = g.history[:i]
+	g.changed(gamer)
+	g.World.Emit(event.Event{Kind: event.Undo, Player: gamer.Name, Room: gamer.InPlace.ID, Target: last.command})
+	g.notifyOthers(gamer, msg.T("undo.say", gamer.Name))
+	return gamer.T("undo.done", last.command)
+}
+
+// mark ставит отметку name - снимок мира в памяти, к которому игрок может вернуться
+func (g *Game) mark(gamer *user.User, name string) string {
+	marks, ok := g.checkpoints[gamer.Name]
+	if !ok {
+		marks = make(map[string]checkpoint)
+		g.checkpoints[gamer.Name] = marks
+	}
+	marks[name] = checkpoint{snap: state.Capture(g.World, g.players()), changes: g.changes}
+	return gamer.T("mark.done", name)
+}
+
+// back возвращает мир к отметке игрока; отменять ходы до неё уже нельзя.
+// Как и с "отменить", вернуться можно, только пока после отметки мир менял лишь сам игрок
+func (g *Game) back(gamer *user.User, name string) string {
+	cp, ok := g.checkpoints[gamer.Name][name]
+	if !ok {
+		return gamer.T("back.none", name)
+	}
+	if other := g.changedSince(gamer, cp.changes); other != "" {
+		return gamer.T("back.other", other)
+	}
+	if err := cp.snap.Restore(g.World, g.players()); err != nil {
+		return gamer.T("load.fail", err)
+	}
+	g.history = nil
+	g.changed(gamer)
+	g.World.Emit(event.Event{Kind: event.Back, Player: gamer.Name, Room: gamer.InPlace.ID, Target: name})
+	g.notifyOthers(gamer, msg.T("back.say", gamer.Name, name))
+	return gamer.T("back.done", name)
+}
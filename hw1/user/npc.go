@@ -0,0 +1,136 @@
+package user
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/room"
+)
+
+// conversation - с кем игрок говорит и на какой реплике остановился
+type conversation struct {
+	npc  *room.NPC
+	node string
+}
+
+// Conversation - с каким персонажем игрок говорит и на какой реплике; пусто - ни с каким
+func (u *User) Conversation() (npc, node string) {
+	if u.talk == nil {
+		return "", ""
+	}
+	return u.talk.npc.Name, u.talk.node
+}
+
+// Resume продолжает разговор с персонажем из комнаты игрока с реплики node;
+// если такого персонажа или реплики нет, игрок ни с кем не говорит
+func (u *User) Resume(npc, node string) {
+	u.talk = nil
+	if n := u.InPlace.NPC(npc); n != nil && n.Nodes[node] != nil {
+		u.talk = &conversation{npc: n, node: node}
+	}
+}
+
+// Talk начинает разговор с персонажем с первой реплики
+func (u *User) Talk(name string) string {
+	n := u.InPlace.NPC(name)
+	if n == nil {
+		return u.T("npc.no", name)
+	}
+	u.talk = &conversation{npc: n, node: n.Start}
+	u.InPlace.Announce(u, msg.T("npc.say", u.Name, name))
+	return u.speak(n, n.Start)
+}
+
+// Say отвечает персонажу фразой или номером ответа;
+// если разговор ещё не начат, ответ выбирается из первой реплики
+func (u *User) Say(name, phrase string) string {
+	n := u.InPlace.NPC(name)
+	if n == nil {
+		return u.T("npc.no", name)
+	}
+	node := n.Start
+	if u.talk != nil && u.talk.npc == n {
+		node = u.talk.node
+	}
+
+	choices := u.choices(n.Nodes[node])
+	var c *room.Choice
+	if idx, err := strconv.Atoi(phrase); err == nil && idx >= 1 && idx <= len(choices) {
+		c = choices[idx-1]
+	}
+	for _, ch := range choices {
+		if c == nil && (strings.EqualFold(ch.Say, phrase) || strings.EqualFold(u.word(ch.Say), phrase)) {
+			c = ch
+		}
+	}
+	if c == nil {
+		return u.T("npc.huh", name)
+	}
+
+	if c.Take != "" {
+		u.Worn, _ = item.Remove(u.Worn, c.Take)
+		u.emit(event.Event{Kind: event.Hand, Item: c.Take, Target: name})
+	}
+	for _, e := range c.Effects {
+		u.apply(e)
+	}
+	u.InPlace.Announce(u, msg.T("npc.say", u.Name, name))
+
+	u.talk = nil
+	parts := make([]string, 0, 2)
+	if c.Reply != "" {
+		parts = append(parts, u.word(c.Reply))
+	}
+	if c.Next != "" {
+		u.talk = &conversation{npc: n, node: c.Next}
+		parts = append(parts, u.speak(n, c.Next))
+	}
+	if len(parts) == 0 {
+		return u.T("npc.silent", name)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (u *User) examineNPC(name string) string {
+	n := u.InPlace.NPC(name)
+	switch {
+	case n == nil:
+		return u.T("no_such")
+	case n.Desc == "":
+		return u.T("examine", name)
+	}
+	return u.word(n.Desc)
+}
+
+// choices - ответы, которые игрок может дать сейчас
+func (u *User) choices(node *room.Node) []*room.Choice {
+	if node == nil {
+		return nil
+	}
+	sc := &room.Scene{Room: u.InPlace, Who: u}
+	res := make([]*room.Choice, 0, len(node.Choices))
+	for _, c := range node.Choices {
+		if sc.Match(c.If) && (c.Take == "" || u.Has(c.Take)) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// speak - реплика персонажа: "кот: мяу? можно ответить - погладить, накормить"
+func (u *User) speak(n *room.NPC, id string) string {
+	node := n.Nodes[id]
+	text := u.T("npc.line", n.Name, node.Text)
+	choices := u.choices(node)
+	if len(choices) == 0 {
+		return text
+	}
+	says := make([]string, 0, len(choices))
+	for _, c := range choices {
+		says = append(says, u.word(c.Say))
+	}
+	return u.T("npc.choices", text, says)
+}
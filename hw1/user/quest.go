@@ -0,0 +1,58 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/room"
+)
+
+// CheckQuests отмечает выполненные цели заданий;
+// за выполненное задание игрок получает сообщение, а соседи узнают об этом
+func (u *User) CheckQuests() {
+	sc := &room.Scene{Room: u.InPlace, Who: u}
+	for _, q := range u.World.Quests {
+		if !q.Update(u.Done, sc.Match) {
+			continue
+		}
+		if q.Win != "" {
+			u.Notify(msg.Raw(q.Win))
+		}
+		u.InPlace.Announce(u, msg.T("quest.say", u.Name))
+		u.emit(event.Event{Kind: event.Quest, Target: q.ID})
+	}
+}
+
+// Finished - выполнены все задания мира, игра для игрока окончена
+func (u *User) Finished() bool {
+	if len(u.World.Quests) == 0 {
+		return false
+	}
+	for _, q := range u.World.Quests {
+		if !q.Complete(u.Done) {
+			return false
+		}
+	}
+	return true
+}
+
+// mission - что напоминают задания в комнате, где стоит игрок
+func (u *User) mission() string {
+	mission := u.word(u.InPlace.MissionText)
+	for _, q := range u.World.Quests {
+		left := q.Left(u.Done)
+		if len(left) == 0 || !q.Shown(u.InPlace.ID) {
+			continue
+		}
+		for i, text := range left {
+			left[i] = u.word(text)
+		}
+		m := u.T("quest.todo", q.Intro, strings.Join(left, u.T("quest.and")))
+		if mission != "" {
+			mission += " "
+		}
+		mission += strings.TrimSpace(m)
+	}
+	return mission
+}
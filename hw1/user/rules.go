@@ -0,0 +1,39 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+// finish применяет эффекты, накопленные триггерами, и дописывает к ответу их реплики
+func (u *User) finish(sc *room.Scene, text string) string {
+	for _, e := range sc.Effects {
+		u.apply(e)
+	}
+	if len(sc.Say) == 0 {
+		return text
+	}
+	says := make([]string, 0, len(sc.Say))
+	for _, s := range sc.Say {
+		says = append(says, u.word(s))
+	}
+	say := strings.Join(says, " ")
+	if text == "" {
+		return say
+	}
+	return text + " " + say
+}
+
+func (u *User) apply(e rules.Effect) {
+	u.World.Apply(e, u.InPlace, u.Name)
+	if e.Give != "" {
+		u.emit(event.Event{Kind: event.Give, Item: e.Give})
+		// если в сумках нет места - подарок остаётся у ног
+		if it := u.World.NewItem(e.Give); !u.AddInInventory(it) {
+			u.InPlace.PutItem(it)
+		}
+	}
+}
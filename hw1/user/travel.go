@@ -0,0 +1,39 @@
+package user
+
+import "strings"
+
+// Travel ведёт игрока кратчайшим путём через открытые двери, комната за комнатой;
+// если туда можно попасть только через закрытую дверь, игрок доходит до неё и останавливается
+func (u *User) Travel(place string) string {
+	to := u.World.FindRoom(place)
+	if e := u.InPlace.Exit(place); e != nil {
+		to = e.To
+	}
+	if to == nil {
+		return u.T("go.no", place)
+	}
+	if to == u.InPlace {
+		return u.T("go.here", place)
+	}
+
+	path := u.World.Path(u.InPlace, to, false)
+	if path == nil {
+		path = u.World.Path(u.InPlace, to, true)
+	}
+	if path == nil {
+		return u.T("go.no", place)
+	}
+
+	answers := make([]string, 0, len(path))
+	for _, e := range path {
+		from := u.InPlace
+		answers = append(answers, u.GoTo(e.Label))
+		// цели в комнатах по пути засчитываются сразу, а не только в конце
+		u.CheckQuests()
+		if u.InPlace == from {
+			// дверь закрыта или триггер не пустил - дальше не идём
+			break
+		}
+	}
+	return strings.Join(answers, "\n")
+}
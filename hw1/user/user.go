@@ -1,159 +1,410 @@
 package user
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/Keniden/vk-homework/game/event"
 	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/msg"
 	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/world"
 )
 
 type User struct {
-	InPlace  *room.Room
-	Items    []*item.Item
-	Backpack bool
+	Name    string
+	World   *world.World
+	InPlace *room.Room
+	Worn    []*item.Item
+	Inbox   []msg.Text
+	// Lang - язык, на котором игрок видит ответы и сообщения
+	Lang *msg.Locale
+	// Admin - игроку доступны команды мастера
+	Admin bool
+	// Done - выполненные цели заданий, ключи - quest.Quest.Key
+	Done map[string]bool
+
+	talk *conversation
 }
 
-func NewUser(InPlace *room.Room) *User {
-	return &User{
-		InPlace:  InPlace,
-		Items:    make([]*item.Item, 0),
-		Backpack: false,
+func NewUser(Name string, World *world.World) *User {
+	u := &User{
+		Name:    Name,
+		World:   World,
+		InPlace: World.Start,
+		Worn:    make([]*item.Item, 0),
+		Done:    make(map[string]bool),
+		Lang:    World.Locale("ru"),
 	}
+	u.InPlace.Enter(u)
+	u.emit(event.Event{Kind: event.Join})
+	return u
 }
 
-func (u *User) Look() string {
-	r := u.InPlace
-	toGo := make([]string, 0, len(r.ToGO))
-	for _, next := range r.ToGO {
-		if next != nil {
-			toGo = append(toGo, next.Name)
-		}
+func (u *User) Nick() string {
+	return u.Name
+}
+
+func (u *User) Notify(t msg.Text) {
+	u.Inbox = append(u.Inbox, t)
+}
+
+// Messages отдаёт накопившиеся сообщения от других игроков на языке игрока и очищает их
+func (u *User) Messages() []string {
+	if len(u.Inbox) == 0 {
+		return nil
 	}
-	items := make([]string, 0, len(r.Items))
-	for _, it := range r.Items {
-		if it != nil && it.Name != "" {
-			items = append(items, it.Name)
-		}
+	msgs := make([]string, 0, len(u.Inbox))
+	for _, t := range u.Inbox {
+		msgs = append(msgs, u.Lang.Render(t))
 	}
+	u.Inbox = nil
+	return msgs
+}
 
-	var mainPart string
-	if r.Name == "комната" && len(items) == 0 && !r.Backpack {
-		mainPart = "пустая комната."
-	} else {
-		tablePart := "на столе: "
-		if len(items) == 0 {
-			tablePart += "ничего"
-		} else {
-			tablePart += strings.Join(items, ", ")
-		}
+// T - сообщение каталога на языке игрока
+func (u *User) T(id string, args ...any) string {
+	return u.Lang.Sprint(id, args...)
+}
 
-		if r.Backpack {
-			tablePart += ", на стуле: рюкзак"
+// word переводит текст из описания мира
+func (u *User) word(s string) string {
+	return u.Lang.Word(s)
+}
+
+// emit сообщает наблюдателям мира о том, что сделал игрок; комната по умолчанию - та, где он стоит
+func (u *User) emit(e event.Event) {
+	e.Player = u.Name
+	if e.Room == "" {
+		e.Room = u.InPlace.ID
+	}
+	u.World.Emit(e)
+}
+
+// Quit убирает игрока из мира
+func (u *User) Quit() {
+	u.InPlace.Leave(u)
+	u.InPlace.Announce(u, msg.T("quit.say", u.Name))
+	u.emit(event.Event{Kind: event.Leave})
+}
+
+func (u *User) Look() string {
+	r := u.InPlace
+	toGo := r.ExitLabels()
+	// предметы перечисляются по местам комнаты, пустые места не упоминаются, если не Always
+	parts := make([]string, 0, len(r.Surfaces))
+	for _, s := range r.Surfaces {
+		names := make([]string, 0)
+		for _, it := range r.ItemsOn(s) {
+			names = append(names, it.Name)
 		}
-		mainPart = r.LookDesc + tablePart
-
-		mission := r.MissionText
-		if r.Name == "кухня" {
-			if u.Backpack {
-				mission = "надо идти в универ."
-			} else {
-				mission = "надо собрать рюкзак и идти в универ."
-			}
+		if len(names) == 0 && !s.Always {
+			continue
 		}
-
-		if mission != "" {
-			if !strings.HasSuffix(mainPart, ".") {
-				mainPart += ", " + mission
-			} else {
-				mainPart += " " + mission
-			}
+		if len(names) == 0 {
+			names = append(names, u.T("look.nothing"))
 		}
+		parts = append(parts, u.T("look.place", s.Name, names))
+	}
+	sc := u.lookScene()
+
+	exits := u.T("exits", toGo)
+	if len(toGo) == 0 {
+		exits = u.T("exits", u.T("exits.none"))
+	}
 
-		if !strings.HasSuffix(mainPart, ".") {
-			mainPart += "."
+	others := make([]string, 0, len(r.NPCs)+len(r.Visitors))
+	for _, n := range r.NPCs {
+		others = append(others, n.Name)
+	}
+	for _, v := range r.Visitors {
+		if v != u {
+			others = append(others, v.Nick())
 		}
 	}
 
-	exits := "можно пройти - "
-	if len(toGo) == 0 {
-		exits += "некуда"
-	} else {
-		exits += strings.Join(toGo, ", ")
+	// триггеры могли подменить описание и задание текстами мира
+	look := room.Look{
+		Desc:    u.word(sc.Desc),
+		Items:   strings.Join(parts, ", "),
+		Mission: u.word(sc.Mission),
+		Exits:   exits,
+		Empty:   r.Empty(),
 	}
+	if len(others) > 0 {
+		look.Others = u.T("look.others", others)
+	}
+	tmpl := r.Template
+	if tmpl == "" {
+		tmpl = room.DefaultTemplate
+	}
+	text, err := sc.Describe(u.word(tmpl), look)
+	if err != nil {
+		// шаблоны проверяются при загрузке мира, сюда попадать не должны
+		text = err.Error()
+	}
+	return u.finish(sc, text)
+}
 
-	return mainPart + " " + exits
+// lookScene - сцена осмотра комнаты; триггеры могут подменить в ней описание и задание
+func (u *User) lookScene() *room.Scene {
+	sc := &room.Scene{Who: u, Desc: u.word(u.InPlace.LookDesc), Mission: u.mission()}
+	u.InPlace.Fire(room.OnLook, sc)
+	return sc
 }
 
 func (u *User) GoTo(place string) string {
-	for _, p := range u.InPlace.ToGO {
-		if p.Name == place {
+	e := u.InPlace.Exit(place)
+	if e == nil {
+		return u.T("go.no", place)
+	}
+	if e.Closed() {
+		return u.T("go.closed", e.Door.Name)
+	}
 
-			if place == "улица" && !u.InPlace.Door {
-				return "дверь закрыта"
-			}
+	leave := &room.Scene{Who: u, Target: place}
+	u.InPlace.Fire(room.OnLeave, leave)
+	if leave.Refuse != "" {
+		return u.word(leave.Refuse)
+	}
+	said := u.finish(leave, "")
 
-			u.InPlace = p
+	p := e.To
+	u.moveTo(p, msg.T("go.left", u.Name, p.Name), msg.T("go.came", u.Name))
 
-			toGo := []string{}
-			for _, r := range u.InPlace.ToGO {
-				toGo = append(toGo, r.Name)
-			}
-			if place == "улица" && u.InPlace.Name == "улица" {
-				return "на улице весна. можно пройти - домой"
-			}
-			return fmt.Sprintf("%sможно пройти - %s", p.GoDesc, strings.Join(toGo, ", "))
+	enter := &room.Scene{Who: u, Desc: p.GoDesc}
+	p.Fire(room.OnEnter, enter)
+	if said != "" {
+		enter.Say = append([]string{said}, enter.Say...)
+	}
+	return u.finish(enter, u.word(enter.Desc)+u.T("exits", p.ExitLabels()))
+}
+
+// moveTo переводит игрока в комнату to; соседи по обеим комнатам слышат left и came
+func (u *User) moveTo(to *room.Room, left, came msg.Text) {
+	from := u.InPlace.ID
+	u.InPlace.Leave(u)
+	u.InPlace.Announce(u, left)
+	u.InPlace = to
+	u.talk = nil
+	to.Announce(u, came)
+	to.Enter(u)
+	u.emit(event.Event{Kind: event.Move, From: from})
+}
+
+func (u *User) Wear(name string) string {
+	r := u.InPlace
+	for idx, it := range r.Items {
+		if it.Name != name {
+			continue
+		}
+		if !it.Wearable {
+			return u.T("wear.no", name)
 		}
+		r.Items = append(r.Items[:idx], r.Items[idx+1:]...)
+		it.Place = ""
+		u.Worn = append(u.Worn, it)
+		r.Announce(u, msg.T("wear.say", u.Name, name))
+		u.emit(event.Event{Kind: event.Wear, Item: name})
+		return u.T("wear.done", name)
 	}
-	return fmt.Sprintf("нет пути в %s", place)
+	return u.T("no_such")
 }
 
-func (u *User) PutOnBackpack() string {
-	if u.InPlace.Backpack {
-		u.InPlace.Backpack = false
-		u.Backpack = true
-		return "вы надели: рюкзак"
+// AddInInventory кладёт предмет в первую надетую сумку, где для него есть место
+func (u *User) AddInInventory(it *item.Item) bool {
+	if bag := u.bagFor(it); bag != nil {
+		bag.Put(it)
+		return true
 	}
-	return "нет рюкзака"
+	return false
 }
 
-func (u *User) AddInInventory(item *item.Item) {
-	if u.Backpack {
-		u.Items = append(u.Items, item)
+func (u *User) bagFor(it *item.Item) *item.Item {
+	for _, bag := range u.Worn {
+		if bag.Fits(it) {
+			return bag
+		}
 	}
+	return nil
+}
+
+func (u *User) hasBag() bool {
+	for _, w := range u.Worn {
+		if w.IsContainer() {
+			return true
+		}
+	}
+	return false
 }
 
 func (u *User) Take(item string) string {
-	if !u.Backpack {
-		return "некуда класть"
+	if !u.hasBag() {
+		return u.T("take.no")
 	}
 	for idx, i := range u.InPlace.Items {
 		if i.Name == item {
+			if u.bagFor(i) == nil {
+				return u.T("take.no")
+			}
+			sc := &room.Scene{Who: u, Item: item}
+			u.InPlace.Fire(room.OnTake, sc)
+			if sc.Refuse != "" {
+				return u.word(sc.Refuse)
+			}
 			u.AddInInventory(i)
 			u.InPlace.Items = append(u.InPlace.Items[:idx], u.InPlace.Items[idx+1:]...)
-			return fmt.Sprintf("предмет добавлен в инвентарь: %s", item)
+			u.InPlace.Announce(u, msg.T("take.say", u.Name, item))
+			u.emit(event.Event{Kind: event.Take, Item: item})
+			return u.finish(sc, u.T("take.done", item))
 		}
 	}
 
-	return "нет такого"
+	return u.T("no_such")
+}
+
+// Put кладёт предмет из инвентаря или из комнаты в сумку, которая есть у игрока или лежит рядом
+func (u *User) Put(what, into string) string {
+	bag := item.Find(u.Worn, into)
+	if bag == nil {
+		bag = item.Find(u.InPlace.Items, into)
+	}
+	if bag == nil {
+		return u.T("no_such.x", into)
+	}
+	if !bag.IsContainer() {
+		return u.T("put.no", into)
+	}
+
+	it := item.Find(u.Worn, what)
+	if it == nil {
+		it = item.Find(u.InPlace.Items, what)
+	}
+	if it == nil {
+		return u.T("no_such.x", what)
+	}
+	if !bag.Fits(it) {
+		return u.T("put.fit", what, into)
+	}
+
+	if u.Has(what) {
+		u.Worn, it = item.Remove(u.Worn, what)
+	} else {
+		u.InPlace.Items, it = item.Remove(u.InPlace.Items, what)
+		u.InPlace.Announce(u, msg.T("put.say", u.Name, what, into))
+	}
+	bag.Put(it)
+	u.emit(event.Event{Kind: event.Put, Item: what, Target: into})
+	return u.T("put.done", what, into)
+}
+
+func (u *User) Inventory() string {
+	if len(u.Worn) == 0 {
+		return u.T("inv.empty")
+	}
+	names := make([]string, 0, len(u.Worn))
+	for _, it := range u.Worn {
+		names = append(names, it.Describe(u.word))
+	}
+	return u.T("inv.worn", strings.Join(names, ", "))
+}
+
+// Drop выкладывает предмет из инвентаря в комнату
+func (u *User) Drop(name string) string {
+	var it *item.Item
+	if u.Worn, it = item.Remove(u.Worn, name); it == nil {
+		return u.T("inv.no", name)
+	}
+	it.Place = ""
+	u.InPlace.PutItem(it)
+	u.InPlace.Announce(u, msg.T("drop.say", u.Name, name))
+	u.emit(event.Event{Kind: event.Drop, Item: name})
+	return u.T("drop.done", name)
+}
+
+// Examine описывает предмет из инвентаря или из комнаты
+func (u *User) Examine(name string) string {
+	it := item.Find(u.Worn, name)
+	if it == nil {
+		it = item.Find(u.InPlace.Items, name)
+	}
+	if it == nil {
+		return u.examineNPC(name)
+	}
+
+	desc := u.word(it.Desc)
+	if desc == "" {
+		desc = u.T("examine", name)
+	}
+	if !it.IsContainer() {
+		return desc
+	}
+	if len(it.Contents) == 0 {
+		return u.T("examine.no", desc)
+	}
+	inside := make([]string, 0, len(it.Contents))
+	for _, in := range it.Contents {
+		inside = append(inside, in.Describe(u.word))
+	}
+	return u.T("examine.in", desc, strings.Join(inside, ", "))
 }
 
 func (u *User) Use(item1, item2 string) string {
-	hasItem := false
-	for _, i := range u.Items {
-		if i.Name == item1 {
-			hasItem = true
-			break
-		}
+	if !u.Has(item1) {
+		return u.T("inv.no", item1)
+	}
+
+	sc := &room.Scene{Who: u, Item: item1, Target: item2}
+	u.InPlace.Fire(room.OnUse, sc)
+	if sc.Refuse != "" {
+		return u.word(sc.Refuse)
 	}
-	if !hasItem {
-		return fmt.Sprintf("нет предмета в инвентаре - %s", item1)
+	return u.finish(sc, u.use(sc, item1, item2))
+}
+
+func (u *User) use(sc *room.Scene, item1, item2 string) string {
+	for _, rule := range u.World.Rules.Lookup(item1, item2) {
+		if !sc.Match(rule.If) {
+			continue
+		}
+		u.emit(event.Event{Kind: event.Use, Item: item1, Target: item2})
+		for _, e := range rule.Effects {
+			u.apply(e)
+		}
+		if rule.Consume {
+			u.Worn, _ = item.Remove(u.Worn, item1)
+			u.emit(event.Event{Kind: event.Remove, Item: item1})
+		}
+		u.InPlace.Announce(u, msg.T("use.say", u.Name, item1))
+		if rule.Message == "" {
+			return u.T("use.done", item1, item2)
+		}
+		return u.word(rule.Message)
 	}
 
-	if item1 == "ключи" && item2 == "дверь" {
-		u.InPlace.UnlockDoor()
-		return "дверь открыта"
+	// ключ от двери рядом открывает её и без отдельного правила
+	if d := u.InPlace.Door(item2); d != nil && d.Key == item1 {
+		if !d.Locked {
+			return u.T("door.was", d.Name)
+		}
+		d.Locked = false
+		u.emit(event.Event{Kind: event.Use, Item: item1, Target: item2})
+		u.emit(event.Event{Kind: event.Unlock, Door: d.ID})
+		u.InPlace.Announce(u, msg.T("door.say", u.Name, d.Name))
+		return u.T("door.open", d.Name)
 	}
-	return "не к чему применить"
+	return u.T("use.no")
+}
+
+// Has - есть ли предмет в инвентаре, надетые вещи тоже считаются
+func (u *User) Has(name string) bool {
+	return item.Find(u.Worn, name) != nil
+}
 
+func (u *User) Wearing(name string) bool {
+	for _, w := range u.Worn {
+		if w.Name == name {
+			return true
+		}
+	}
+	return false
 }
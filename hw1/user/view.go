@@ -0,0 +1,77 @@
+package user
+
+import "github.com/Keniden/vk-homework/game/item"
+
+// View - что видит игрок, для клиентов вроде веб-интерфейса.
+// Name - названия как в мире, их можно подставлять в команды, Title - они же на языке игрока
+type View struct {
+	Room      string     `json:"room"`
+	Title     string     `json:"title"`
+	Exits     []ExitView `json:"exits"`
+	Items     []ItemView `json:"items"`
+	Inventory []ItemView `json:"inventory"`
+	Mission   string     `json:"mission,omitempty"`
+	NPCs      []string   `json:"npcs,omitempty"`
+	Players   []string   `json:"players,omitempty"`
+	Turn      int        `json:"turn"`
+	Finished  bool       `json:"finished"`
+}
+
+type ExitView struct {
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	To     string `json:"to"`
+	Door   string `json:"door,omitempty"`
+	Closed bool   `json:"closed,omitempty"`
+}
+
+type ItemView struct {
+	Name     string     `json:"name"`
+	Title    string     `json:"title"`
+	Place    string     `json:"place,omitempty"`
+	Contents []ItemView `json:"contents,omitempty"`
+}
+
+func (u *User) View() View {
+	r := u.InPlace
+	// задание то же, что в описании комнаты; эффекты триггеров осмотра тут не применяются
+	v := View{
+		Room:      r.ID,
+		Title:     u.word(r.Name),
+		Exits:     make([]ExitView, 0, len(r.Exits)),
+		Items:     u.itemViews(r.Items),
+		Inventory: u.itemViews(u.Worn),
+		Mission:   u.word(u.lookScene().Mission),
+		Turn:      u.World.Clock.Turn,
+		Finished:  u.Finished(),
+	}
+	for _, e := range r.Exits {
+		ev := ExitView{Name: e.Label, Title: u.word(e.Label), To: e.To.ID, Closed: e.Closed()}
+		if e.Door != nil {
+			ev.Door = e.Door.Name
+		}
+		v.Exits = append(v.Exits, ev)
+	}
+	for _, n := range r.NPCs {
+		v.NPCs = append(v.NPCs, n.Name)
+	}
+	for _, other := range r.Visitors {
+		if other != u {
+			v.Players = append(v.Players, other.Nick())
+		}
+	}
+	return v
+}
+
+func (u *User) itemViews(items []*item.Item) []ItemView {
+	res := make([]ItemView, 0, len(items))
+	for _, it := range items {
+		res = append(res, ItemView{
+			Name:     it.Name,
+			Title:    u.word(it.Name),
+			Place:    it.Place,
+			Contents: u.itemViews(it.Contents),
+		})
+	}
+	return res
+}
@@ -0,0 +1,220 @@
+package world
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+// Problem - недочёт в мире; Error - с ним игру не пройти, остальное - предупреждения
+type Problem struct {
+	Error bool
+	Msg   string
+}
+
+func (p Problem) String() string {
+	if p.Error {
+		return "error: " + p.Msg
+	}
+	return "warning: " + p.Msg
+}
+
+// Check ищет недостижимые комнаты, односторонние выходы, одинаковые названия комнат,
+// предметы, которые ни на что не влияют, и запертые двери без ключа
+func (w *World) Check() []Problem {
+	var res []Problem
+	add := func(isErr bool, format string, args ...any) {
+		res = append(res, Problem{Error: isErr, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	reached := w.reachable(nil)
+	for _, r := range w.Rooms {
+		if !reached[r] {
+			add(true, "room %q is unreachable from %q", r.ID, w.Start.ID)
+		}
+	}
+
+	for _, r := range w.Rooms {
+		for _, e := range r.Exits {
+			if !leadsTo(e.To, r) {
+				add(false, "room %q: exit %q to %q is one-way", r.ID, e.Label, e.To.ID)
+			}
+		}
+	}
+
+	byName := map[string][]string{}
+	for _, r := range w.Rooms {
+		byName[r.Name] = append(byName[r.Name], r.ID)
+	}
+	for _, r := range w.Rooms {
+		if ids := byName[r.Name]; len(ids) > 1 && ids[0] == r.ID {
+			add(false, "rooms %q have the same name %q", ids, r.Name)
+		}
+	}
+
+	used := w.usedItems()
+	names := make([]string, 0, len(w.Items))
+	for name := range w.Items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		it := w.Items[name]
+		if !used[name] && !it.Wearable && !it.IsContainer() {
+			add(false, "item %q is not used by any rule, door, quest or trigger", name)
+		}
+	}
+
+	exists := w.obtainable(nil)
+	unlocked := w.unlockedByEffects()
+	for _, d := range w.Doors {
+		if !d.Locked || unlocked[d.ID] {
+			continue
+		}
+		switch {
+		case d.Key == "":
+			add(true, "door %q is locked and has no key", d.ID)
+		case !exists[d.Key]:
+			add(true, "door %q is locked with key %q that does not exist", d.ID, d.Key)
+		case !w.obtainable(d)[d.Key]:
+			add(true, "door %q is locked with key %q that lies only behind it", d.ID, d.Key)
+		}
+	}
+	return res
+}
+
+// reachable - комнаты, куда можно дойти от старта; через дверь closed не пройти
+func (w *World) reachable(closed *room.Door) map[*room.Room]bool {
+	seen := map[*room.Room]bool{w.Start: true}
+	queue := []*room.Room{w.Start}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		for _, e := range r.Exits {
+			if closed != nil && e.Door == closed {
+				continue
+			}
+			if !seen[e.To] {
+				seen[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return seen
+}
+
+func leadsTo(from, to *room.Room) bool {
+	for _, e := range from.Exits {
+		if e.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// conds и effects - все условия и эффекты мира: правил, триггеров, событий, заданий и диалогов
+func (w *World) conds() []rules.Cond {
+	var res []rules.Cond
+	for _, r := range w.Rules {
+		res = append(res, r.If)
+	}
+	for _, td := range w.triggers {
+		res = append(res, td.If)
+	}
+	for _, q := range w.Quests {
+		for _, o := range q.Objectives {
+			res = append(res, o.If)
+		}
+	}
+	w.eachChoice(func(c *room.Choice) {
+		res = append(res, c.If)
+	})
+	return res
+}
+
+func (w *World) effects() []rules.Effect {
+	var res []rules.Effect
+	for _, r := range w.Rules {
+		res = append(res, r.Effects...)
+	}
+	for _, td := range w.triggers {
+		res = append(res, td.Effects...)
+	}
+	for _, ev := range w.Events {
+		res = append(res, ev.Effects...)
+	}
+	w.eachChoice(func(c *room.Choice) {
+		res = append(res, c.Effects...)
+	})
+	return res
+}
+
+func (w *World) eachChoice(f func(c *room.Choice)) {
+	for _, r := range w.Rooms {
+		for _, n := range r.NPCs {
+			for _, node := range n.Nodes {
+				for _, c := range node.Choices {
+					f(c)
+				}
+			}
+		}
+	}
+}
+
+func (w *World) usedItems() map[string]bool {
+	used := map[string]bool{}
+	for _, r := range w.Rules {
+		used[r.Item] = true
+		used[r.Target] = true
+	}
+	for _, d := range w.Doors {
+		used[d.Key] = true
+	}
+	for _, c := range w.conds() {
+		used[c.Item] = true
+		used[c.Target] = true
+		used[c.Has] = true
+		used[c.Wearing] = true
+	}
+	w.eachChoice(func(c *room.Choice) {
+		used[c.Take] = true
+	})
+	for _, e := range w.effects() {
+		used[e.Remove] = true
+	}
+	return used
+}
+
+// obtainable - предметы, которые лежат в комнатах, куда можно дойти, не открывая closed,
+// или появляются от эффектов
+func (w *World) obtainable(closed *room.Door) map[string]bool {
+	res := make(map[string]bool, len(w.Items))
+	var add func(items []*item.Item)
+	add = func(items []*item.Item) {
+		for _, it := range items {
+			res[it.Name] = true
+			add(it.Contents)
+		}
+	}
+	for r := range w.reachable(closed) {
+		add(r.Items)
+	}
+	for _, e := range w.effects() {
+		res[e.Spawn] = true
+		res[e.Give] = true
+	}
+	return res
+}
+
+func (w *World) unlockedByEffects() map[string]bool {
+	res := map[string]bool{}
+	for _, e := range w.effects() {
+		if e.Unlock != "" {
+			res[e.Unlock] = true
+		}
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package world
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	w, err := Parse([]byte(`{
+	"rooms": [
+		{"id": "a", "name": "зал", "items": ["ключ", "ваза", "лом"], "exits": ["b", {"to": "c", "door": "решётка"}]},
+		{"id": "b", "name": "зал", "exits": ["a", {"to": "d", "door": "сейф"}]},
+		{"id": "c", "name": "подвал"},
+		{"id": "d", "name": "хранилище", "exits": ["b", {"to": "f", "door": "люк"}]},
+		{"id": "e", "name": "чердак"},
+		{"id": "f", "name": "кладовая", "items": ["фомка"], "exits": ["d"]}
+	],
+	"doors": [
+		{"id": "решётка", "name": "решётка", "locked": true, "key": "ключ"},
+		{"id": "сейф", "name": "сейф", "locked": true, "key": "код"},
+		{"id": "люк", "name": "люк", "locked": true, "key": "фомка"}
+	],
+	"rules": [
+		{"item": "лом", "target": "стена", "effects": [{"spawn": "кирпич"}]}
+	]
+}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var got []string
+	for _, p := range w.Check() {
+		got = append(got, p.String())
+	}
+	want := []string{
+		`error: room "e" is unreachable from "a"`,
+		`warning: room "a": exit "подвал" to "c" is one-way`,
+		`warning: rooms ["a" "b"] have the same name "зал"`,
+		`warning: item "ваза" is not used by any rule, door, quest or trigger`,
+		`error: door "сейф" is locked with key "код" that does not exist`,
+		`error: door "люк" is locked with key "фомка" that lies only behind it`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExport(t *testing.T) {
+	w, err := Parse([]byte(`{
+	"rooms": [
+		{"name": "коридор", "exits": ["кухня", {"to": "улица", "door": "входная", "two_way": true, "back_label": "домой"}]},
+		{"name": "кухня", "exits": ["коридор"]},
+		{"name": "улица"}
+	],
+	"doors": [{"id": "входная", "name": "дверь", "locked": true, "key": "ключи"}]
+}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	dot := w.DOT()
+	for _, line := range []string{
+		`"коридор" [label="коридор", style=bold];`,
+		`"коридор" -> "кухня" [label="кухня"];`,
+		`"коридор" -> "улица" [label="улица: дверь, ключ ключи", style=dashed];`,
+		`"улица" -> "коридор" [label="домой: дверь, ключ ключи", style=dashed];`,
+	} {
+		if !strings.Contains(dot, "\t"+line+"\n") {
+			t.Errorf("dot has no %s:\n%s", line, dot)
+		}
+	}
+
+	mermaid := w.Mermaid()
+	for _, line := range []string{
+		`r0(["коридор"])`,
+		`r0 -->|"кухня"| r1`,
+		`r2 -.->|"домой: дверь, ключ ключи"| r0`,
+	} {
+		if !strings.Contains(mermaid, "\t"+line+"\n") {
+			t.Errorf("mermaid has no %s:\n%s", line, mermaid)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package world
+
+import (
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+// Apply меняет мир по эффекту; here - комната, где он случился, для эффектов без Room,
+// who - игрок, из-за которого, или пусто. Give тут не обрабатывается - подарок получает игрок, это его дело
+func (w *World) Apply(e rules.Effect, here *room.Room, who string) {
+	r := here
+	if e.Room != "" {
+		if er := w.Room(e.Room); er != nil {
+			r = er
+		}
+	}
+	at := ""
+	if r != nil {
+		at = r.ID
+		if e.Set != "" {
+			r.SetFlag(e.Set, true)
+			w.Emit(event.Event{Kind: event.Set, Player: who, Room: r.ID, Target: e.Set})
+		}
+		if e.Unset != "" {
+			r.SetFlag(e.Unset, false)
+			w.Emit(event.Event{Kind: event.Unset, Player: who, Room: r.ID, Target: e.Unset})
+		}
+		if e.Spawn != "" {
+			r.PutItem(w.NewItem(e.Spawn))
+			w.Emit(event.Event{Kind: event.Spawn, Player: who, Room: r.ID, Item: e.Spawn})
+		}
+		if e.Remove != "" {
+			var it *item.Item
+			if r.Items, it = item.Remove(r.Items, e.Remove); it != nil {
+				w.Emit(event.Event{Kind: event.Remove, Player: who, Room: r.ID, Item: e.Remove})
+			}
+		}
+	}
+	if d := w.Door(e.Unlock); d != nil {
+		d.Locked = false
+		w.Emit(event.Event{Kind: event.Unlock, Player: who, Room: at, Door: d.ID})
+	}
+	if d := w.Door(e.Lock); d != nil {
+		d.Locked = true
+		w.Emit(event.Event{Kind: event.Lock, Player: who, Room: at, Door: d.ID})
+	}
+	if e.Cancel != "" {
+		w.Clock.Cancel(e.Cancel)
+	}
+	if e.Schedule != "" {
+		w.Clock.Schedule(e.Schedule, e.In)
+	}
+}
+
+// Tick делает ход и применяет события, которым пора случиться; отдаёт случившиеся
+func (w *World) Tick() []*rules.Event {
+	var fired []*rules.Event
+	for _, id := range w.Clock.Tick() {
+		ev, ok := w.Events[id]
+		if !ok {
+			continue
+		}
+		here := w.Room(ev.Room)
+		for _, e := range ev.Effects {
+			w.Apply(e, here, "")
+		}
+		if ev.Every > 0 {
+			w.Clock.Schedule(id, ev.Every)
+		}
+		fired = append(fired, ev)
+	}
+	return fired
+}
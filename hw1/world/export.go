@@ -0,0 +1,79 @@
+package world
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Keniden/vk-homework/game/room"
+)
+
+// DOT рисует карту мира для graphviz: dot -Tpng map.dot > map.png.
+// Стартовая комната выделена, выходы через двери - пунктиром с названием двери и ключа
+func (w *World) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph world {\n\tnode [shape=box];\n")
+	for _, r := range w.Rooms {
+		attrs := "label=" + dotQuote(r.Name)
+		if r == w.Start {
+			attrs += ", style=bold"
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", dotQuote(r.ID), attrs)
+	}
+	for _, r := range w.Rooms {
+		for _, e := range r.Exits {
+			attrs := "label=" + dotQuote(exitLabel(e))
+			if e.Door != nil {
+				attrs += ", style=dashed"
+			}
+			fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotQuote(r.ID), dotQuote(e.To.ID), attrs)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid рисует ту же карту для markdown: ```mermaid ... ```
+func (w *World) Mermaid() string {
+	ids := make(map[string]string, len(w.Rooms))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, r := range w.Rooms {
+		ids[r.ID] = fmt.Sprintf("r%d", i)
+		shape := `["%s"]`
+		if r == w.Start {
+			shape = `(["%s"])`
+		}
+		fmt.Fprintf(&b, "\t%s"+shape+"\n", ids[r.ID], mermaidQuote(r.Name))
+	}
+	for _, r := range w.Rooms {
+		for _, e := range r.Exits {
+			arrow := "-->"
+			if e.Door != nil {
+				arrow = "-.->"
+			}
+			label := exitLabel(e)
+			fmt.Fprintf(&b, "\t%s %s|\"%s\"| %s\n", ids[r.ID], arrow, mermaidQuote(label), ids[e.To.ID])
+		}
+	}
+	return b.String()
+}
+
+// exitLabel - подпись выхода: "улица: дверь, ключ ключи"
+func exitLabel(e *room.Exit) string {
+	if e.Door == nil {
+		return e.Label
+	}
+	label := e.Label + ": " + e.Door.Name
+	if e.Door.Locked && e.Door.Key != "" {
+		label += ", ключ " + e.Door.Key
+	}
+	return label
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
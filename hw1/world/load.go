@@ -0,0 +1,862 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/quest"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+/*
+	файл мира описывает комнаты в json:
+
+	{
+		"start": "кухня",
+		"rooms": [
+			{
+				"name": "кухня",
+				"look": "ты находишься на кухне, ",
+				"go": "кухня, ничего интересного. ",
+				"mission": "надо собрать рюкзак и идти в универ.",
+				"items": ["чай"],
+				"exits": ["коридор"]
+			}
+		]
+	}
+
+	look - описание комнаты при осмотре, go - при входе, mission - задание комнаты.
+	template - шаблон описания при осмотре (text/template), по умолчанию
+	"{{.Desc}}, {{.Items}}, {{.Mission}}. {{.Others}}. {{.Exits}}", например:
+
+	"template": "{{if .Empty}}пустая комната{{else}}{{.Items}}{{end}}, {{.Mission}}. {{.Exits}}"
+
+	.Desc - look, .Items - предметы по местам, .Others - кто ещё здесь, .Exits - выходы;
+	в условиях - .Empty, .Flag "флаг", .Has "предмет", .Wearing "предмет".
+	запятые и точки от пустых частей убираются сами.
+
+	id - необязательный постоянный идентификатор комнаты (по умолчанию совпадает с name),
+	на него ссылаются exits, start и сохранения.
+	flags - начальные флаги комнаты, их проверяют и меняют правила.
+
+	npcs - персонажи комнаты с диалогом:
+
+	"npcs": [
+		{
+			"name": "кот", "desc": "рыжий кот", "start": "привет",
+			"dialogue": [
+				{"id": "привет", "text": "мяу?", "choices": [
+					{"say": "погладить", "reply": "кот мурлычет"},
+					{"say": "угостить", "if": {"has": "колбаса"}, "take": "колбаса", "effects": [{"give": "ключ"}], "next": "сытый"}
+				]},
+				{"id": "сытый", "text": "мрр"}
+			]
+		}
+	]
+
+	start - первая реплика (по умолчанию первая в dialogue), say - ответ игрока,
+	виден только при выполнении if, take - предмет, который игрок отдаёт,
+	reply - ответ персонажа, next - следующая реплика, без неё разговор окончен.
+
+	предмет - это имя или объект:
+
+	{"name": "рюкзак", "desc": "старый рюкзак", "weight": 2, "capacity": 10, "wearable": true, "place": "стул", "contents": [...]}
+
+	desc показывает "осмотреть", weight по умолчанию 1, capacity - сколько веса влезает внутрь (0 - не контейнер),
+	wearable - можно надеть, place - ID места комнаты, где лежит предмет, по умолчанию первое место.
+
+	surfaces - места комнаты, по ним строится "осмотреться":
+
+	"surfaces": [{"id": "полка", "name": "на полке", "always": true}, {"id": "пол", "name": "на полу"}]
+
+	always - упоминать место, даже когда на нём ничего нет.
+	без surfaces в комнате есть стол ("на столе", always), а незнакомый place предмета
+	сам становится местом с таким названием.
+	Первое описание предмета служит образцом, когда правила создают предмет с тем же именем.
+
+	выход - это ID комнаты или объект:
+
+	{"to": "улица", "label": "на улицу", "door": "входная", "two_way": true, "back_label": "домой"}
+
+	label - как выход называется в комнате (по умолчанию имя комнаты to),
+	two_way - добавить и обратный выход с названием back_label через ту же дверь.
+
+	doors - двери, на которые ссылаются выходы:
+
+	"doors": [{"id": "входная", "name": "дверь", "locked": true, "key": "ключи"}]
+
+	key - предмет, которым дверь открывается командой "применить <key> <name>".
+
+	triggers - поведение комнат на события enter, look, take, use и leave:
+
+	"triggers": [
+		{"room": "кухня", "on": "look", "if": {"wearing": "рюкзак"}, "mission": "надо идти в универ."},
+		{"room": "кладовка", "on": "enter", "if": {"no_flag": "свет"}, "desc": "темно. ", "say": "щёлк!", "effects": [{"set": "свет"}]}
+	]
+
+	desc и mission подменяют описание (.Desc шаблона) и задание (для enter desc - текст при входе),
+	say добавляет реплику к ответу, refuse отменяет действие с этим ответом,
+	effects - те же эффекты, что и в rules.
+
+	quests - задания из целей, их выполнение следит за каждым игроком отдельно:
+
+	"quests": [
+		{
+			"id": "универ", "intro": "надо", "rooms": ["кухня"], "ordered": true,
+			"objectives": [
+				{"id": "рюкзак", "text": "собрать рюкзак", "if": {"wearing": "рюкзак", "has": "конспекты"}},
+				{"id": "улица", "text": "идти в универ", "if": {"room": "улица"}}
+			],
+			"win": "задание выполнено"
+		}
+	]
+
+	пока задание не выполнено, в комнатах rooms (по умолчанию - во всех) к заданию комнаты
+	добавляется "<intro> <оставшиеся цели через и>.", win приходит игроку сообщением,
+	а когда выполнены все задания, игра для него окончена.
+
+	events - то, что случается само, через несколько ходов; ход - любая команда игрока:
+
+	"events": [
+		{"id": "автобус", "at": 10, "room": "улица", "message": "автобус уехал", "effects": [{"remove": "автобус"}]},
+		{"id": "захлопнулась", "room": "коридор", "message": "дверь захлопнулась", "effects": [{"lock": "входная"}]}
+	]
+
+	at - ход, на котором событие случится (0 - только если его запланирует эффект
+	{"schedule": "захлопнулась", "in": 3}; in считается вместе с текущим ходом,
+	in 1 - в конце этого же хода), every - повторять через столько ходов,
+	message слышат все в room, а если room не указана - все игроки.
+	эффект {"cancel": "захлопнулась"} отменяет запланированное событие.
+
+	locales - перевод текстов мира на другие языки каталога сообщений (ru, en):
+
+	"locales": {
+		"en": {"words": {"кухня": "kitchen", "ты находишься на кухне, ": "you are in the kitchen, "}},
+		"ru": {"forms": {"комната": {"вин": "комнату"}}}
+	}
+
+	words переводит названия и описания целиком, как они записаны в мире;
+	forms - формы слов по падежам для сообщений вроде "вася ушёл в комнату".
+
+	rules - таблица "применить <item> <target>":
+
+	"rules": [
+		{
+			"item": "ключи", "target": "шкаф",
+			"if": {"room": "комната", "no_flag": "шкаф открыт"},
+			"effects": [{"set": "шкаф открыт"}, {"spawn": "куртка"}],
+			"message": "шкаф открыт",
+			"consume": false
+		}
+	]
+*/
+
+// Error - ошибка в файле мира. Для синтаксических ошибок Line - строка, где json сломался,
+// а для ошибок в содержании - строка, где начинается объект (комната, дверь, правило):
+// неизвестный выход из многострочной комнаты указывает на её открывающую скобку, а не на сам выход
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+type roomDef struct {
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	Look     string       `json:"look"`
+	Go       string       `json:"go"`
+	Mission  string       `json:"mission"`
+	Template string       `json:"template"`
+	Items    []*itemDef   `json:"items"`
+	Surfaces []surfaceDef `json:"surfaces"`
+	Exits    []exitDef    `json:"exits"`
+	NPCs     []npcDef     `json:"npcs"`
+	Flags    []string     `json:"flags"`
+
+	line int
+}
+
+type itemDef item.Item
+
+// UnmarshalJSON разрешает писать предмет просто строкой - именем
+func (d *itemDef) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*d = itemDef{Weight: 1}
+		return json.Unmarshal(data, &d.Name)
+	}
+	type plain struct {
+		Name     string     `json:"name"`
+		Desc     string     `json:"desc"`
+		Weight   *int       `json:"weight"`
+		Capacity int        `json:"capacity"`
+		Wearable bool       `json:"wearable"`
+		Place    string     `json:"place"`
+		Contents []*itemDef `json:"contents"`
+	}
+	var p plain
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return err
+	}
+	*d = itemDef{Name: p.Name, Desc: p.Desc, Weight: 1, Capacity: p.Capacity, Wearable: p.Wearable, Place: p.Place}
+	if p.Weight != nil {
+		d.Weight = *p.Weight
+	}
+	for _, in := range p.Contents {
+		d.Contents = append(d.Contents, (*item.Item)(in))
+	}
+	return nil
+}
+
+type surfaceDef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Always bool   `json:"always"`
+}
+
+type exitDef struct {
+	To        string `json:"to"`
+	Label     string `json:"label"`
+	Door      string `json:"door"`
+	TwoWay    bool   `json:"two_way"`
+	BackLabel string `json:"back_label"`
+}
+
+// UnmarshalJSON разрешает писать выход просто строкой - ID комнаты
+func (e *exitDef) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = exitDef{}
+		return json.Unmarshal(data, &e.To)
+	}
+	type plain exitDef
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode((*plain)(e))
+}
+
+type npcDef struct {
+	Name     string    `json:"name"`
+	Desc     string    `json:"desc"`
+	Start    string    `json:"start"`
+	Dialogue []nodeDef `json:"dialogue"`
+}
+
+type nodeDef struct {
+	ID      string         `json:"id"`
+	Text    string         `json:"text"`
+	Choices []*room.Choice `json:"choices"`
+}
+
+type doorDef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Locked bool   `json:"locked"`
+	Key    string `json:"key"`
+
+	line int
+}
+
+type ruleDef struct {
+	rules.Rule
+
+	line int
+}
+
+type triggerDef struct {
+	Room    string         `json:"room"`
+	On      room.Event     `json:"on"`
+	If      rules.Cond     `json:"if"`
+	Desc    *string        `json:"desc"`
+	Mission *string        `json:"mission"`
+	Say     string         `json:"say"`
+	Refuse  string         `json:"refuse"`
+	Effects []rules.Effect `json:"effects"`
+
+	line int
+}
+
+type eventDef struct {
+	rules.Event
+
+	line int
+}
+
+type questDef struct {
+	quest.Quest
+
+	line int
+}
+
+type localeDef struct {
+	Words map[string]string            `json:"words"`
+	Forms map[string]map[string]string `json:"forms"`
+}
+
+type worldDef struct {
+	Start    string
+	Rooms    []roomDef
+	Doors    []doorDef
+	Rules    []ruleDef
+	Triggers []triggerDef
+	Quests   []questDef
+	Events   []eventDef
+	Locales  map[string]localeDef
+
+	startLine   int
+	localesLine int
+}
+
+func Load(path string) (*World, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := Parse(data)
+	var werr *Error
+	if errors.As(err, &werr) {
+		werr.File = path
+	}
+	return w, err
+}
+
+func Parse(data []byte) (*World, error) {
+	def, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return build(def)
+}
+
+func decode(data []byte) (*worldDef, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	def := &worldDef{}
+
+	if err := expectDelim(dec, data, '{'); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, jsonError(data, err, offset)
+		}
+		switch tok {
+		case "start":
+			offset := dec.InputOffset()
+			def.startLine = lineAt(data, offset)
+			if err := dec.Decode(&def.Start); err != nil {
+				return nil, jsonError(data, err, offset)
+			}
+		case "rooms":
+			err := decodeList(dec, data, func(line int) any {
+				def.Rooms = append(def.Rooms, roomDef{line: line})
+				return &def.Rooms[len(def.Rooms)-1]
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "doors":
+			err := decodeList(dec, data, func(line int) any {
+				def.Doors = append(def.Doors, doorDef{line: line})
+				return &def.Doors[len(def.Doors)-1]
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "triggers":
+			err := decodeList(dec, data, func(line int) any {
+				def.Triggers = append(def.Triggers, triggerDef{line: line})
+				return &def.Triggers[len(def.Triggers)-1]
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "quests":
+			err := decodeList(dec, data, func(line int) any {
+				def.Quests = append(def.Quests, questDef{line: line})
+				return &def.Quests[len(def.Quests)-1].Quest
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "events":
+			err := decodeList(dec, data, func(line int) any {
+				def.Events = append(def.Events, eventDef{line: line})
+				return &def.Events[len(def.Events)-1].Event
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "locales":
+			offset := dec.InputOffset()
+			def.localesLine = lineAt(data, offset)
+			if err := dec.Decode(&def.Locales); err != nil {
+				return nil, jsonError(data, err, offset)
+			}
+		case "rules":
+			err := decodeList(dec, data, func(line int) any {
+				def.Rules = append(def.Rules, ruleDef{line: line})
+				return &def.Rules[len(def.Rules)-1].Rule
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, &Error{Line: lineAt(data, offset), Msg: fmt.Sprintf("unknown field %v", tok)}
+		}
+	}
+	if err := expectDelim(dec, data, '}'); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &Error{Line: lineAt(data, dec.InputOffset()), Msg: "unexpected data after world definition"}
+	}
+	return def, nil
+}
+
+func build(def *worldDef) (*World, error) {
+	if len(def.Rooms) == 0 {
+		return nil, &Error{Line: 1, Msg: "world has no rooms"}
+	}
+
+	w := &World{Items: make(map[string]*item.Item)}
+	doors := make(map[string]*room.Door, len(def.Doors))
+	for _, dd := range def.Doors {
+		if dd.ID == "" || dd.Name == "" {
+			return nil, &Error{Line: dd.line, Msg: "door needs id and name"}
+		}
+		if _, ok := doors[dd.ID]; ok {
+			return nil, &Error{Line: dd.line, Msg: fmt.Sprintf("duplicate door %q", dd.ID)}
+		}
+		d := &room.Door{ID: dd.ID, Name: dd.Name, Locked: dd.Locked, Key: dd.Key}
+		doors[d.ID] = d
+		w.Doors = append(w.Doors, d)
+	}
+
+	byID := make(map[string]*room.Room, len(def.Rooms))
+	for _, rd := range def.Rooms {
+		if rd.Name == "" {
+			return nil, &Error{Line: rd.line, Msg: "room without name"}
+		}
+		id := rd.ID
+		if id == "" {
+			id = rd.Name
+		}
+		if _, ok := byID[id]; ok {
+			return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("duplicate room %q", id)}
+		}
+		r := room.NewRoom(rd.Name, rd.Look, rd.Go, rd.Mission, []*item.Item{})
+		r.ID = id
+		r.Template = rd.Template
+		if err := addSurfaces(r, rd); err != nil {
+			return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: %v", rd.Name, err)}
+		}
+		for _, d := range rd.Items {
+			it := (*item.Item)(d)
+			if err := checkItem(it, w); err != nil {
+				return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: %v", rd.Name, err)}
+			}
+			r.PutItem(it)
+		}
+		for _, f := range rd.Flags {
+			r.SetFlag(f, true)
+		}
+		byID[id] = r
+		w.Rooms = append(w.Rooms, r)
+	}
+
+	for i, rd := range def.Rooms {
+		from := w.Rooms[i]
+		for _, ed := range rd.Exits {
+			to, ok := byID[ed.To]
+			if !ok {
+				return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: unknown exit %q", rd.Name, ed.To)}
+			}
+			var d *room.Door
+			if ed.Door != "" {
+				if d, ok = doors[ed.Door]; !ok {
+					return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: unknown door %q", rd.Name, ed.Door)}
+				}
+			}
+			if err := addExit(from, &room.Exit{Label: ed.Label, To: to, Door: d}); err != nil {
+				return nil, &Error{Line: rd.line, Msg: err.Error()}
+			}
+			if ed.TwoWay {
+				if err := addExit(to, &room.Exit{Label: ed.BackLabel, To: from, Door: d}); err != nil {
+					return nil, &Error{Line: rd.line, Msg: err.Error()}
+				}
+			}
+		}
+	}
+
+	w.Events = make(map[string]*rules.Event, len(def.Events))
+	for _, ed := range def.Events {
+		if ed.ID == "" {
+			return nil, &Error{Line: ed.line, Msg: "event without id"}
+		}
+		if _, ok := w.Events[ed.ID]; ok {
+			return nil, &Error{Line: ed.line, Msg: fmt.Sprintf("duplicate event %q", ed.ID)}
+		}
+		ev := ed.Event
+		w.Events[ev.ID] = &ev
+	}
+	for _, ed := range def.Events {
+		if _, ok := byID[ed.Room]; ed.Room != "" && !ok {
+			return nil, &Error{Line: ed.line, Msg: fmt.Sprintf("event %q: unknown room %q", ed.ID, ed.Room)}
+		}
+		if ed.At < 0 || ed.Every < 0 {
+			return nil, &Error{Line: ed.line, Msg: fmt.Sprintf("event %q: negative at or every", ed.ID)}
+		}
+		if err := checkEffects(ed.Effects, byID, doors, w.Events); err != nil {
+			return nil, &Error{Line: ed.line, Msg: fmt.Sprintf("event %q: %v", ed.ID, err)}
+		}
+		if ed.At > 0 {
+			w.Clock.Schedule(ed.ID, ed.At)
+		}
+	}
+
+	for i, rd := range def.Rooms {
+		r := w.Rooms[i]
+		for _, nd := range rd.NPCs {
+			n, err := nd.build(byID, doors, w.Events)
+			if err == nil && r.NPC(n.Name) != nil {
+				err = fmt.Errorf("duplicate npc %q", n.Name)
+			}
+			if err != nil {
+				return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: %v", rd.Name, err)}
+			}
+			r.NPCs = append(r.NPCs, n)
+		}
+	}
+
+	for _, rd := range def.Rules {
+		if err := checkRule(rd, byID, doors, w.Events); err != nil {
+			return nil, err
+		}
+		rule := rd.Rule
+		w.Rules = append(w.Rules, &rule)
+	}
+
+	for _, td := range def.Triggers {
+		r, ok := byID[td.Room]
+		if !ok {
+			return nil, &Error{Line: td.line, Msg: fmt.Sprintf("trigger: unknown room %q", td.Room)}
+		}
+		switch td.On {
+		case room.OnEnter, room.OnLook, room.OnTake, room.OnUse, room.OnLeave:
+		default:
+			return nil, &Error{Line: td.line, Msg: fmt.Sprintf("trigger: unknown event %q", td.On)}
+		}
+		if err := checkEffects(td.Effects, byID, doors, w.Events); err != nil {
+			return nil, &Error{Line: td.line, Msg: "trigger: " + err.Error()}
+		}
+		r.On(td.On, td.trigger())
+		w.triggers = append(w.triggers, td)
+	}
+
+	quests := make(map[string]bool, len(def.Quests))
+	for _, qd := range def.Quests {
+		if err := checkQuest(qd, byID); err != nil {
+			return nil, err
+		}
+		if quests[qd.ID] {
+			return nil, &Error{Line: qd.line, Msg: fmt.Sprintf("duplicate quest %q", qd.ID)}
+		}
+		quests[qd.ID] = true
+		q := qd.Quest
+		w.Quests = append(w.Quests, &q)
+	}
+
+	w.Locales = make(map[string]*msg.Locale, len(def.Locales))
+	for lang, ld := range def.Locales {
+		l, err := msg.New(lang, ld.Words, ld.Forms)
+		if err != nil {
+			return nil, &Error{Line: def.localesLine, Msg: err.Error()}
+		}
+		w.Locales[lang] = l
+	}
+	// шаблон описания проверяется и разбирается сразу для каждого перевода
+	for i, rd := range def.Rooms {
+		tmpl := rd.Template
+		if tmpl == "" {
+			tmpl = room.DefaultTemplate
+		}
+		srcs := []string{tmpl}
+		for _, l := range w.Locales {
+			srcs = append(srcs, l.Word(tmpl))
+		}
+		for _, src := range srcs {
+			t, err := w.Rooms[i].Compile(src)
+			if err == nil {
+				_, err = (room.Look{}).Render(t)
+			}
+			if err != nil {
+				return nil, &Error{Line: rd.line, Msg: fmt.Sprintf("room %q: template: %v", w.Rooms[i].Name, err)}
+			}
+		}
+	}
+
+	if def.Start == "" {
+		w.Start = w.Rooms[0]
+		return w, nil
+	}
+	start, ok := byID[def.Start]
+	if !ok {
+		return nil, &Error{Line: def.startLine, Msg: fmt.Sprintf("unknown start room %q", def.Start)}
+	}
+	w.Start = start
+	return w, nil
+}
+
+// addSurfaces расставляет в комнате места из описания; в старых мирах без surfaces
+// место предмета - это просто название вроде "на стуле", оно и становится местом
+func addSurfaces(r *room.Room, rd roomDef) error {
+	if len(rd.Surfaces) == 0 {
+		for _, d := range rd.Items {
+			if d.Place != "" && r.Surface(d.Place) == nil {
+				r.Surfaces = append(r.Surfaces, &room.Surface{ID: d.Place, Name: d.Place})
+			}
+		}
+		return nil
+	}
+	r.Surfaces = nil
+	for _, sd := range rd.Surfaces {
+		if sd.ID == "" || sd.Name == "" {
+			return fmt.Errorf("surface needs id and name")
+		}
+		if r.Surface(sd.ID) != nil {
+			return fmt.Errorf("duplicate surface %q", sd.ID)
+		}
+		r.Surfaces = append(r.Surfaces, &room.Surface{ID: sd.ID, Name: sd.Name, Always: sd.Always})
+	}
+	for _, d := range rd.Items {
+		if d.Place != "" && r.Surface(d.Place) == nil {
+			return fmt.Errorf("item %q: unknown surface %q", d.Name, d.Place)
+		}
+	}
+	return nil
+}
+
+func addExit(from *room.Room, e *room.Exit) error {
+	if e.Label == "" {
+		e.Label = e.To.Name
+	}
+	if from.Exit(e.Label) != nil {
+		return fmt.Errorf("room %q: duplicate exit %q", from.Name, e.Label)
+	}
+	from.AddExit(e)
+	return nil
+}
+
+// checkItem проверяет предмет с содержимым и запоминает образцы
+func checkItem(it *item.Item, w *World) error {
+	if it.Name == "" {
+		return fmt.Errorf("item without name")
+	}
+	if it.Weight < 0 || it.Capacity < 0 {
+		return fmt.Errorf("item %q: negative weight or capacity", it.Name)
+	}
+	for _, in := range it.Contents {
+		if err := checkItem(in, w); err != nil {
+			return err
+		}
+	}
+	if it.TotalWeight()-it.Weight > it.Capacity {
+		return fmt.Errorf("item %q: contents do not fit", it.Name)
+	}
+	if _, ok := w.Items[it.Name]; !ok {
+		w.Items[it.Name] = it.Clone()
+	}
+	return nil
+}
+
+func checkRule(rd ruleDef, rooms map[string]*room.Room, doors map[string]*room.Door, events map[string]*rules.Event) error {
+	if rd.Item == "" || rd.Target == "" {
+		return &Error{Line: rd.line, Msg: "rule needs item and target"}
+	}
+	if _, ok := rooms[rd.If.Room]; rd.If.Room != "" && !ok {
+		return &Error{Line: rd.line, Msg: fmt.Sprintf("rule %s/%s: unknown room %q", rd.Item, rd.Target, rd.If.Room)}
+	}
+	if err := checkEffects(rd.Effects, rooms, doors, events); err != nil {
+		return &Error{Line: rd.line, Msg: fmt.Sprintf("rule %s/%s: %v", rd.Item, rd.Target, err)}
+	}
+	return nil
+}
+
+func checkQuest(qd questDef, rooms map[string]*room.Room) error {
+	if qd.ID == "" || len(qd.Objectives) == 0 {
+		return &Error{Line: qd.line, Msg: "quest needs id and objectives"}
+	}
+	for _, id := range qd.Rooms {
+		if _, ok := rooms[id]; !ok {
+			return &Error{Line: qd.line, Msg: fmt.Sprintf("quest %q: unknown room %q", qd.ID, id)}
+		}
+	}
+	seen := make(map[string]bool, len(qd.Objectives))
+	for _, o := range qd.Objectives {
+		if o.ID == "" || o.Text == "" {
+			return &Error{Line: qd.line, Msg: fmt.Sprintf("quest %q: objective needs id and text", qd.ID)}
+		}
+		if seen[o.ID] {
+			return &Error{Line: qd.line, Msg: fmt.Sprintf("quest %q: duplicate objective %q", qd.ID, o.ID)}
+		}
+		seen[o.ID] = true
+		if _, ok := rooms[o.If.Room]; o.If.Room != "" && !ok {
+			return &Error{Line: qd.line, Msg: fmt.Sprintf("quest %q/%s: unknown room %q", qd.ID, o.ID, o.If.Room)}
+		}
+	}
+	return nil
+}
+
+func checkEffects(effects []rules.Effect, rooms map[string]*room.Room, doors map[string]*room.Door, events map[string]*rules.Event) error {
+	for _, e := range effects {
+		if _, ok := rooms[e.Room]; e.Room != "" && !ok {
+			return fmt.Errorf("unknown room %q", e.Room)
+		}
+		for _, id := range []string{e.Unlock, e.Lock} {
+			if _, ok := doors[id]; id != "" && !ok {
+				return fmt.Errorf("unknown door %q", id)
+			}
+		}
+		for _, id := range []string{e.Schedule, e.Cancel} {
+			if _, ok := events[id]; id != "" && !ok {
+				return fmt.Errorf("unknown event %q", id)
+			}
+		}
+		if e.In < 0 {
+			return fmt.Errorf("event %q: negative in", e.Schedule)
+		}
+	}
+	return nil
+}
+
+// build проверяет диалог персонажа: ссылки next и эффекты ответов
+func (nd npcDef) build(rooms map[string]*room.Room, doors map[string]*room.Door, events map[string]*rules.Event) (*room.NPC, error) {
+	if nd.Name == "" || len(nd.Dialogue) == 0 {
+		return nil, fmt.Errorf("npc needs name and dialogue")
+	}
+	n := &room.NPC{Name: nd.Name, Desc: nd.Desc, Start: nd.Start, Nodes: make(map[string]*room.Node, len(nd.Dialogue))}
+	if n.Start == "" {
+		n.Start = nd.Dialogue[0].ID
+	}
+	for _, node := range nd.Dialogue {
+		if _, ok := n.Nodes[node.ID]; ok {
+			return nil, fmt.Errorf("npc %q: duplicate dialogue node %q", nd.Name, node.ID)
+		}
+		n.Nodes[node.ID] = &room.Node{Text: node.Text, Choices: node.Choices}
+	}
+	if _, ok := n.Nodes[n.Start]; !ok {
+		return nil, fmt.Errorf("npc %q: unknown start node %q", nd.Name, n.Start)
+	}
+	for _, node := range nd.Dialogue {
+		for _, c := range node.Choices {
+			if c.Say == "" {
+				return nil, fmt.Errorf("npc %q/%s: choice without say", nd.Name, node.ID)
+			}
+			if _, ok := n.Nodes[c.Next]; c.Next != "" && !ok {
+				return nil, fmt.Errorf("npc %q/%s: unknown next node %q", nd.Name, node.ID, c.Next)
+			}
+			if _, ok := rooms[c.If.Room]; c.If.Room != "" && !ok {
+				return nil, fmt.Errorf("npc %q/%s: unknown room %q", nd.Name, node.ID, c.If.Room)
+			}
+			if err := checkEffects(c.Effects, rooms, doors, events); err != nil {
+				return nil, fmt.Errorf("npc %q/%s: %v", nd.Name, node.ID, err)
+			}
+		}
+	}
+	return n, nil
+}
+
+// trigger превращает описание из файла в триггер комнаты
+func (td triggerDef) trigger() room.Trigger {
+	return func(s *room.Scene) {
+		if !s.Match(td.If) {
+			return
+		}
+		if td.Desc != nil {
+			s.Desc = *td.Desc
+		}
+		if td.Mission != nil {
+			s.Mission = *td.Mission
+		}
+		if td.Say != "" {
+			s.Say = append(s.Say, td.Say)
+		}
+		s.Effects = append(s.Effects, td.Effects...)
+		s.Refuse = td.Refuse
+	}
+}
+
+// decodeList читает json массив, next отдаёт, куда класть очередной элемент, начинающийся на строке line
+func decodeList(dec *json.Decoder, data []byte, next func(line int) any) error {
+	if err := expectDelim(dec, data, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		offset := dec.InputOffset()
+		if err := dec.Decode(next(lineAt(data, offset))); err != nil {
+			return jsonError(data, err, offset)
+		}
+	}
+	return expectDelim(dec, data, ']')
+}
+
+func expectDelim(dec *json.Decoder, data []byte, want json.Delim) error {
+	offset := dec.InputOffset()
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonError(data, err, offset)
+	}
+	if tok != want {
+		return &Error{Line: lineAt(data, offset), Msg: fmt.Sprintf("expected %q, got %v", want, tok)}
+	}
+	return nil
+}
+
+// base - смещение начала значения, от него json считает UnmarshalTypeError.Offset
+func jsonError(data []byte, err error, base int64) error {
+	line := lineAt(data, base)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	// Decoder посреди значения сообщает о конце файла синтаксической ошибкой
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input":
+		return &Error{Line: lineAt(data, int64(len(data))), Msg: "unexpected end of file"}
+	case errors.As(err, &syntaxErr):
+		line = lineAt(data, syntaxErr.Offset-1)
+	case errors.As(err, &typeErr):
+		line = lineAt(data, base+typeErr.Offset)
+	}
+	return &Error{Line: line, Msg: err.Error()}
+}
+
+// lineAt пропускает пробелы и запятые после offset, чтобы указывать на начало значения
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	for offset < int64(len(data)) && bytes.IndexByte([]byte(" \t\r\n,:"), data[offset]) >= 0 {
+		offset++
+	}
+	if offset == int64(len(data)) && offset > 0 {
+		offset--
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
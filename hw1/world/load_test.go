@@ -0,0 +1,69 @@
+package world
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	w, err := Parse([]byte(`{
+	"start": "b",
+	"rooms": [
+		{"name": "a", "items": ["x", "y"], "exits": ["b"]},
+		{"name": "b", "exits": [{"to": "c", "label": "наружу", "door": "d", "two_way": true, "back_label": "домой"}]},
+		{"name": "c"}
+	],
+	"doors": [{"id": "d", "name": "дверь", "locked": true, "key": "x"}]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Start.Name != "b" {
+		t.Fatalf("start = %q, want b", w.Start.Name)
+	}
+	a := w.Room("a")
+	if len(a.Items) != 2 || len(a.Exits) != 1 || a.Exit("b").To != w.Start {
+		t.Fatalf("room a built wrong: %+v", a)
+	}
+	out, back := w.Start.Exit("наружу"), w.Room("c").Exit("домой")
+	if out == nil || back == nil || out.To != w.Room("c") || back.To != w.Start {
+		t.Fatalf("two-way exit built wrong: %+v %+v", out, back)
+	}
+	if out.Door != back.Door || !out.Closed() || out.Door.Key != "x" {
+		t.Fatalf("door should be shared and locked: %+v %+v", out.Door, back.Door)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"syntax", "{\n\"rooms\": [\n{\"name\": \"a\",}\n]\n}", "line 3:"},
+		{"type", "{\n\"rooms\": [\n{\"name\": 1}\n]\n}", "line 3:"},
+		{"unknown room field", "{\n\"rooms\": [\n{\"name\": \"a\"},\n{\"name\": \"b\", \"doors\": 1}\n]\n}", "line 4: json: unknown field"},
+		{"unknown exit", "{\n\"rooms\": [\n{\"name\": \"a\"},\n{\"name\": \"b\", \"exits\": [\"c\"]}\n]\n}", `line 4: room "b": unknown exit "c"`},
+		{"duplicate", "{\n\"rooms\": [\n{\"name\": \"a\"},\n{\"name\": \"a\"}\n]\n}", `line 4: duplicate room "a"`},
+		{"start", "{\n\"rooms\": [{\"name\": \"a\"}],\n\"start\": \"b\"\n}", `line 3: unknown start room "b"`},
+		{"unknown field", "{\n\"rooms\": [],\n\"items\": []\n}", "line 3: unknown field items"},
+		{"rule room", "{\n\"rooms\": [{\"name\": \"a\"}],\n\"rules\": [\n{\"item\": \"x\", \"target\": \"y\", \"if\": {\"room\": \"b\"}}\n]\n}", `line 4: rule x/y: unknown room "b"`},
+		{"exit in multiline room", "{\n\"rooms\": [\n{\"name\": \"a\"},\n{\n\t\"name\": \"b\",\n\t\"exits\": [\"c\"]\n}\n]\n}", `line 4: room "b": unknown exit "c"`},
+		{"unknown door", "{\n\"rooms\": [\n{\"name\": \"a\", \"exits\": [{\"to\": \"a\", \"door\": \"d\"}]}\n]\n}", `line 3: room "a": unknown door "d"`},
+		{"duplicate exit", "{\n\"rooms\": [\n{\"name\": \"a\", \"exits\": [\"b\", {\"to\": \"a\", \"label\": \"b\"}]},\n{\"name\": \"b\"}\n]\n}", `line 3: room "a": duplicate exit "b"`},
+		{"trigger event", "{\n\"rooms\": [{\"name\": \"a\"}],\n\"triggers\": [\n{\"room\": \"a\", \"on\": \"sneeze\"}\n]\n}", `line 4: trigger: unknown event "sneeze"`},
+		{"quest objective", "{\n\"rooms\": [{\"name\": \"a\"}],\n\"quests\": [\n{\"id\": \"q\", \"objectives\": [{\"id\": \"o\", \"text\": \"o\"}, {\"id\": \"o\", \"text\": \"o\"}]}\n]\n}", `line 4: quest "q": duplicate objective "o"`},
+		{"npc next", "{\n\"rooms\": [\n{\"name\": \"a\", \"npcs\": [{\"name\": \"кот\", \"dialogue\": [{\"id\": \"x\", \"choices\": [{\"say\": \"y\", \"next\": \"z\"}]}]}]}\n]\n}", `line 3: room "a": npc "кот"/x: unknown next node "z"`},
+		{"surface", "{\n\"rooms\": [\n{\"name\": \"a\", \"surfaces\": [{\"id\": \"пол\", \"name\": \"на полу\"}], \"items\": [{\"name\": \"x\", \"place\": \"стол\"}]}\n]\n}", `line 3: room "a": item "x": unknown surface "стол"`},
+		{"template", "{\n\"rooms\": [\n{\"name\": \"a\", \"template\": \"{{.Items}\"}\n]\n}", `line 3: room "a": template:`},
+		{"locale", "{\n\"rooms\": [{\"name\": \"a\"}],\n\"locales\": {\"de\": {}}\n}", `line 3: unknown language "de"`},
+		{"eof", "{\n\"rooms\": [\n", "line 2: unexpected end of file"},
+		{"eof in room", "{\n\"rooms\": [\n{\"name\": \"a\"", "line 3: unexpected end of file"},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.data))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: got error %v, want %q", c.name, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package world
+
+import "github.com/Keniden/vk-homework/game/room"
+
+// Path - кратчайший путь из from в to в виде выходов по порядку;
+// locked - можно ли идти через запертые двери. nil - пути нет, пустой путь - from и to совпадают
+func (w *World) Path(from, to *room.Room, locked bool) []*room.Exit {
+	type step struct {
+		from *room.Room
+		exit *room.Exit
+	}
+	prev := map[*room.Room]step{}
+	seen := map[*room.Room]bool{from: true}
+	queue := []*room.Room{from}
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		if r == to {
+			break
+		}
+		for _, e := range r.Exits {
+			if seen[e.To] || e.Closed() && !locked {
+				continue
+			}
+			seen[e.To] = true
+			prev[e.To] = step{r, e}
+			queue = append(queue, e.To)
+		}
+	}
+	if !seen[to] {
+		return nil
+	}
+	path := []*room.Exit{}
+	for r := to; r != from; r = prev[r].from {
+		path = append([]*room.Exit{prev[r].exit}, path...)
+	}
+	return path
+}
+
+// FindRoom ищет комнату по ID, а если такой нет - по названию
+func (w *World) FindRoom(name string) *room.Room {
+	if r := w.Room(name); r != nil {
+		return r
+	}
+	for _, r := range w.Rooms {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package world
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	w, err := Parse([]byte(`{
+	"rooms": [
+		{"name": "кухня", "exits": ["коридор"]},
+		{"name": "коридор", "exits": ["кухня", "чулан", {"to": "улица", "door": "входная", "two_way": true, "back_label": "домой"}]},
+		{"name": "чулан", "exits": ["коридор", {"to": "двор", "label": "окно"}]},
+		{"name": "двор", "exits": ["улица"]},
+		{"name": "улица"}
+	],
+	"doors": [{"id": "входная", "name": "дверь", "locked": true, "key": "ключи"}]
+}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	labels := func(from, to string, locked bool) string {
+		path := w.Path(w.FindRoom(from), w.FindRoom(to), locked)
+		if path == nil {
+			return "<nil>"
+		}
+		res := []string{}
+		for _, e := range path {
+			res = append(res, e.Label)
+		}
+		return strings.Join(res, " ")
+	}
+
+	cases := []struct {
+		from, to string
+		locked   bool
+		want     string
+	}{
+		{"кухня", "улица", false, "коридор чулан окно улица"},
+		{"кухня", "улица", true, "коридор улица"},
+		{"улица", "кухня", false, "<nil>"},
+		{"кухня", "кухня", false, ""},
+	}
+	for _, c := range cases {
+		if got := labels(c.from, c.to, c.locked); got != c.want {
+			t.Errorf("%s -> %s (locked %v): %q, want %q", c.from, c.to, c.locked, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package world
+
+import (
+	"github.com/Keniden/vk-homework/game/clock"
+	"github.com/Keniden/vk-homework/game/event"
+	"github.com/Keniden/vk-homework/game/item"
+	"github.com/Keniden/vk-homework/game/msg"
+	"github.com/Keniden/vk-homework/game/quest"
+	"github.com/Keniden/vk-homework/game/room"
+	"github.com/Keniden/vk-homework/game/rules"
+)
+
+type World struct {
+	Rooms  []*room.Room
+	Start  *room.Room
+	Doors  []*room.Door
+	Rules  rules.Table
+	Items  map[string]*item.Item
+	Quests []*quest.Quest
+	Events map[string]*rules.Event
+	Clock  clock.Clock
+	// Locales - переводы текстов мира по языкам
+	Locales map[string]*msg.Locale
+	// Bus - наблюдатели за изменениями мира: аналитика, достижения, журнал
+	Bus event.Bus
+
+	// triggers - описания триггеров из файла, по ним Check ищет, где используются предметы
+	triggers []triggerDef
+}
+
+// NewItem делает предмет по образцу из описания мира или простой предмет весом 1
+func (w *World) NewItem(name string) *item.Item {
+	if proto, ok := w.Items[name]; ok {
+		it := proto.Clone()
+		it.Contents = nil
+		return it
+	}
+	return item.New(name)
+}
+
+// Emit сообщает наблюдателям о событии, ход проставляется сам
+func (w *World) Emit(e event.Event) {
+	e.Turn = w.Clock.Turn
+	w.Bus.Emit(e)
+}
+
+func (w *World) Room(id string) *room.Room {
+	for _, r := range w.Rooms {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (w *World) Door(id string) *room.Door {
+	for _, d := range w.Doors {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// Locale - язык для игрока; если мир не переведён на lang, тексты мира остаются как есть,
+// nil - такого языка нет в каталоге
+func (w *World) Locale(lang string) *msg.Locale {
+	if l, ok := w.Locales[lang]; ok {
+		return l
+	}
+	l, err := msg.New(lang, nil, nil)
+	if err != nil {
+		return nil
+	}
+	return l
+}
+
+// Names - все названия, которые игрок может упомянуть в команде:
+// комнаты, выходы, двери, персонажи и предметы
+func (w *World) Names() []string {
+	var names []string
+	var addItems func(items []*item.Item)
+	addItems = func(items []*item.Item) {
+		for _, it := range items {
+			names = append(names, it.Name)
+			addItems(it.Contents)
+		}
+	}
+	for _, r := range w.Rooms {
+		names = append(names, r.ID, r.Name)
+		for _, e := range r.Exits {
+			names = append(names, e.Label)
+		}
+		for _, n := range r.NPCs {
+			names = append(names, n.Name)
+		}
+		addItems(r.Items)
+	}
+	for _, d := range w.Doors {
+		names = append(names, d.Name)
+	}
+	for name := range w.Items {
+		names = append(names, name)
+	}
+	for _, r := range w.Rules {
+		names = append(names, r.Item, r.Target)
+		for _, e := range r.Effects {
+			names = append(names, e.Spawn, e.Give)
+		}
+	}
+	return names
+}